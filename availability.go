@@ -0,0 +1,124 @@
+package ctfbot
+
+import (
+	"context"
+	"time"
+)
+
+// AvailabilityPoll represents a poll asking members when they can play a
+// CTF. The duration of the CTF is split into slots of the same length.
+type AvailabilityPoll struct {
+	ID int
+
+	// Discord IDs of the poll message, and of its channel.
+	ChannelID string
+	MessageID string
+
+	// CTFTime ID of the event, zero if unknown.
+	EventID int
+	Title   string
+
+	// When the first slot starts, how long every slot lasts and how many
+	// there are.
+	Start      time.Time
+	SlotLength time.Duration
+	Slots      int
+
+	CreatedAt time.Time
+}
+
+// SlotStart returns when the i-th slot of the poll starts.
+func (p *AvailabilityPoll) SlotStart(i int) time.Time {
+	return p.Start.Add(time.Duration(i) * p.SlotLength)
+}
+
+func (p *AvailabilityPoll) Validate() error {
+	if p.ChannelID == "" || p.MessageID == "" {
+		return Errorf(EINVALID, "Poll message required.")
+	}
+
+	if p.Title == "" {
+		return Errorf(EINVALID, "Title required.")
+	}
+
+	if p.Start.IsZero() {
+		return Errorf(EINVALID, "Start required.")
+	}
+
+	if p.SlotLength <= 0 || p.Slots <= 0 {
+		return Errorf(EINVALID, "At least one slot required.")
+	}
+
+	return nil
+}
+
+// AvailabilityResponse represents a member being available during one of the
+// slots of an availability poll.
+type AvailabilityResponse struct {
+	ID     int
+	PollID int
+
+	// Discord ID of the member.
+	UserID string
+
+	// Index of the slot.
+	Slot int
+
+	CreatedAt time.Time
+}
+
+func (r *AvailabilityResponse) Validate() error {
+	if r.PollID == 0 {
+		return Errorf(EINVALID, "Poll required.")
+	}
+
+	if r.UserID == "" {
+		return Errorf(EINVALID, "User required.")
+	}
+
+	if r.Slot < 0 {
+		return Errorf(EINVALID, "Invalid slot.")
+	}
+
+	return nil
+}
+
+type AvailabilityService interface {
+	// Creates a new availability poll.
+	CreateAvailabilityPoll(ctx context.Context, poll *AvailabilityPoll) error
+
+	// Retrieves an availability poll by the Discord ID of its message.
+	FindAvailabilityPollByMessageID(ctx context.Context, messageID string) (*AvailabilityPoll, error)
+
+	// Retrieves a list of availability polls by filter.
+	FindAvailabilityPolls(ctx context.Context, filter AvailabilityPollFilter) ([]*AvailabilityPoll, int, error)
+
+	// Replaces the slots a user is available in. An empty list of slots
+	// means the user isn't available at all.
+	SetAvailability(ctx context.Context, pollID int, userID string, slots []int) error
+
+	// Retrieves a list of responses by filter.
+	FindAvailabilityResponses(ctx context.Context, filter AvailabilityResponseFilter) ([]*AvailabilityResponse, int, error)
+}
+
+// AvailabilityPollFilter represents a filter passed to
+// FindAvailabilityPolls().
+type AvailabilityPollFilter struct {
+	ID        *int
+	MessageID *string
+
+	// Limit and offset.
+	Limit  int
+	Offset int
+}
+
+// AvailabilityResponseFilter represents a filter passed to
+// FindAvailabilityResponses().
+type AvailabilityResponseFilter struct {
+	PollID *int
+	UserID *string
+
+	// Limit and offset.
+	Limit  int
+	Offset int
+}
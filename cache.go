@@ -0,0 +1,39 @@
+package ctfbot
+
+import (
+	"context"
+	"time"
+)
+
+// CacheEntry represents a response of an external service, kept to answer
+// the same query again without asking.
+type CacheEntry struct {
+	Key   string
+	Value []byte
+
+	// When the response was fetched from the service.
+	FetchedAt time.Time
+}
+
+func (e *CacheEntry) Validate() error {
+	if e.Key == "" {
+		return Errorf(EINVALID, "Cache key required.")
+	}
+
+	if e.FetchedAt.IsZero() {
+		return Errorf(EINVALID, "Fetch time required.")
+	}
+
+	return nil
+}
+
+type CacheService interface {
+	// Retrieves a cache entry by key.
+	FindCacheEntry(ctx context.Context, key string) (*CacheEntry, error)
+
+	// Creates a cache entry, or replaces the one with the same key.
+	SetCacheEntry(ctx context.Context, entry *CacheEntry) error
+
+	// Permanently deletes the cache entries fetched before a time.
+	DeleteCacheEntries(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,99 @@
+package ctfbot
+
+import (
+	"context"
+	"time"
+)
+
+// Well-known challenge categories.
+const (
+	CategoryPwn    = "pwn"
+	CategoryWeb    = "web"
+	CategoryCrypto = "crypto"
+	CategoryRev    = "rev"
+	CategoryMisc   = "misc"
+)
+
+type Challenge struct {
+	ID    int
+	CTFID int
+	Name  string
+
+	// Challenge metadata.
+	Category string
+	Points   int
+
+	// Discord-related information.
+	ChannelID string
+
+	// Solve status.
+	Solved bool
+	Blood  bool
+
+	// Metadata about creation.
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (c *Challenge) Validate() error {
+	if c.Name == "" {
+		return Errorf(EINVALID, "Name required.")
+	}
+
+	if c.CTFID == 0 {
+		return Errorf(EINVALID, "CTF required.")
+	}
+
+	if c.ChannelID == "" {
+		return Errorf(EINVALID, "Channel required.")
+	}
+
+	if c.Points < 0 {
+		return Errorf(EINVALID, "Points must not be negative.")
+	}
+
+	return nil
+}
+
+type ChallengeService interface {
+	// Creates a new challenge.
+	CreateChallenge(ctx context.Context, chal *Challenge) error
+
+	// Retrieves a challenge by ID.
+	FindChallengeByID(ctx context.Context, id int) (*Challenge, error)
+
+	// Retrieves a challenge by the ID of its Discord channel.
+	FindChallengeByChannelID(ctx context.Context, channelID string) (*Challenge, error)
+
+	// Retrieves a list of challenges by filter.
+	FindChallenges(ctx context.Context, filter ChallengeFilter) ([]*Challenge, int, error)
+
+	// Updates a challenge object.
+	UpdateChallenge(ctx context.Context, id int, upd ChallengeUpdate) (*Challenge, error)
+
+	// Permanently deletes a challenge.
+	DeleteChallenge(ctx context.Context, id int) error
+}
+
+// ChallengeFilter represents a filter passed to FindChallenges().
+type ChallengeFilter struct {
+	ID        *int
+	CTFID     *int
+	Name      *string
+	Category  *string
+	ChannelID *string
+	Solved    *bool
+
+	// Limit and offset.
+	Limit  int
+	Offset int
+}
+
+// ChallengeUpdate represents a filter passed to UpdateChallenge().
+type ChallengeUpdate struct {
+	Name     *string
+	Category *string
+	Points   *int
+	Solved   *bool
+	Blood    *bool
+}
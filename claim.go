@@ -0,0 +1,53 @@
+package ctfbot
+
+import (
+	"context"
+	"time"
+)
+
+// Claim represents a member actively working on a challenge.
+type Claim struct {
+	ID          int
+	ChallengeID int
+
+	// Discord ID of the user working on the challenge.
+	UserID string
+
+	// When the user started working on the challenge.
+	StartedAt time.Time
+}
+
+func (c *Claim) Validate() error {
+	if c.ChallengeID == 0 {
+		return Errorf(EINVALID, "Challenge required.")
+	}
+
+	if c.UserID == "" {
+		return Errorf(EINVALID, "User required.")
+	}
+
+	return nil
+}
+
+type ClaimService interface {
+	// Creates a new claim.
+	CreateClaim(ctx context.Context, claim *Claim) error
+
+	// Retrieves a list of claims by filter.
+	FindClaims(ctx context.Context, filter ClaimFilter) ([]*Claim, int, error)
+
+	// Permanently deletes a claim.
+	DeleteClaim(ctx context.Context, id int) error
+}
+
+// ClaimFilter represents a filter passed to FindClaims().
+type ClaimFilter struct {
+	ID          *int
+	ChallengeID *int
+	CTFID       *int
+	UserID      *string
+
+	// Limit and offset.
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// RunExport exports the channels of a CTF to a zip of Markdown files, like
+// the /export command does, without connecting to the gateway.
+func (m *Main) RunExport(ctx context.Context, args []string) error {
+	f := flag.NewFlagSet("ctfbotd export", flag.ContinueOnError)
+	f.StringVar(&m.ConfigPath, "config-path", DefaultConfigPath, "config file path")
+	name := f.String("ctf", "", "name of the CTF to export")
+	output := f.String("o", "", "output file (default: the name of the CTF, with a .zip extension)")
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return fmt.Errorf("the name of the CTF is required, use -ctf")
+	}
+
+	if *output == "" {
+		*output = strings.Join(strings.Fields(*name), "-") + ".zip"
+	}
+
+	if err := m.loadConfig(); err != nil {
+		return err
+	}
+
+	if err := m.setup(); err != nil {
+		return err
+	}
+
+	if err := m.Discord.OpenREST(); err != nil {
+		return err
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := m.Discord.ExportCTF(ctx, *name, file); err != nil {
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %s to %s\n", *name, *output)
+	return nil
+}
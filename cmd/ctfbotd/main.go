@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"flag"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/havce/ctfbot"
@@ -31,10 +33,45 @@ type Config struct {
 		BotToken            string `toml:"bot_token"`
 		RegistrationChannel string `toml:"registration_channel"`
 		GeneralChannel      string `toml:"general_channel"`
+		ArchiveCategory     string `toml:"archive_category"`
+
+		// Whether to request the privileged message content intent.
+		MessageContent bool `toml:"message_content"`
+
+		// How often solves are fetched from the CTF platforms.
+		SyncInterval time.Duration `toml:"sync_interval"`
+
+		// How often our position on the CTF scoreboards is checked.
+		ScoreboardInterval time.Duration `toml:"scoreboard_interval"`
+
+		// How often the CTFs in the database are checked against the guild.
+		ReconcileInterval time.Duration `toml:"reconcile_interval"`
+
+		// How long before the start of a CTF its players are reminded of it.
+		ReminderOffsets []time.Duration `toml:"reminder_offsets"`
+
+		// How long after the start of a CTF its registrations are closed,
+		// and how long after its end it is archived.
+		CloseAfter   time.Duration `toml:"close_after"`
+		ArchiveAfter time.Duration `toml:"archive_after"`
 	} `toml:"discord"`
 
+	CTFTime struct {
+		// How long CTFTime responses are fresh, and how long after that
+		// they're served while being fetched again.
+		CacheTTL      time.Duration `toml:"cache_ttl"`
+		CacheStaleTTL time.Duration `toml:"cache_stale_ttl"`
+
+		// Whether to keep CTFTime responses in the database, so that they
+		// survive restarts.
+		PersistCache bool `toml:"persist_cache"`
+	} `toml:"ctftime"`
+
 	DB struct {
 		DSN string `toml:"dsn"`
+
+		// Base64 encoded AES key, used to encrypt secrets at rest.
+		EncryptionKey string `toml:"encryption_key"`
 	} `toml:"db"`
 }
 
@@ -46,14 +83,32 @@ const (
 const (
 	DefaultRegistrationChannel = "registration"
 	DefaultGeneralChannel      = "general"
+	DefaultArchiveCategory     = "Archive"
+	DefaultSyncInterval        = 2 * time.Minute
+	DefaultScoreboardInterval  = 5 * time.Minute
+	DefaultReconcileInterval   = 10 * time.Minute
+	DefaultCloseAfter          = 2 * time.Hour
+	DefaultArchiveAfter        = 7 * 24 * time.Hour
 )
 
+var DefaultReminderOffsets = []time.Duration{24 * time.Hour, time.Hour, 10 * time.Minute}
+
 // DefaultConfig returns a new instance of Config with defaults set.
 func DefaultConfig() Config {
 	var config Config
 	config.DB.DSN = DefaultDSN
 	config.Discord.RegistrationChannel = DefaultRegistrationChannel
 	config.Discord.GeneralChannel = DefaultGeneralChannel
+	config.Discord.ArchiveCategory = DefaultArchiveCategory
+	config.Discord.SyncInterval = DefaultSyncInterval
+	config.Discord.ScoreboardInterval = DefaultScoreboardInterval
+	config.Discord.ReconcileInterval = DefaultReconcileInterval
+	config.Discord.ReminderOffsets = DefaultReminderOffsets
+	config.Discord.CloseAfter = DefaultCloseAfter
+	config.Discord.ArchiveAfter = DefaultArchiveAfter
+	config.CTFTime.CacheTTL = ctftime.DefaultCacheTTL
+	config.CTFTime.CacheStaleTTL = ctftime.DefaultCacheStaleTTL
+	config.CTFTime.PersistCache = true
 	return config
 }
 
@@ -67,6 +122,19 @@ func main() {
 
 	m := NewMain()
 
+	// Export a CTF and quit, instead of running the bot.
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		err := m.RunExport(ctx, os.Args[2:])
+		_ = m.Close(ctx)
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(1)
+		} else if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Parse command line flags & load configuration.
 	if err := m.ParseFlagAndConfig(ctx, os.Args[1:]); errors.Is(err, flag.ErrHelp) {
 		os.Exit(1)
@@ -94,6 +162,9 @@ type Main struct {
 
 	DB *sqlite.DB
 
+	// Answers from the CTFTime responses we already have when we can.
+	CTFTime *ctftime.Cache
+
 	Discord *discord.Server
 }
 
@@ -101,6 +172,7 @@ func NewMain() *Main {
 	return &Main{
 		Discord: discord.NewServer(),
 		DB:      sqlite.NewDB(""),
+		CTFTime: ctftime.NewCache(ctftime.NewClient()),
 
 		Config:     DefaultConfig(),
 		ConfigPath: DefaultConfigPath,
@@ -112,6 +184,11 @@ func (m *Main) Close(ctx context.Context) error {
 		_ = m.Discord.Close(ctx)
 	}
 
+	// Responses being fetched in the background are written to the database.
+	if m.CTFTime != nil {
+		_ = m.CTFTime.Close()
+	}
+
 	if m.DB != nil {
 		return m.DB.Close()
 	}
@@ -126,6 +203,11 @@ func (m *Main) ParseFlagAndConfig(ctx context.Context, args []string) error {
 		return err
 	}
 
+	return m.loadConfig()
+}
+
+// loadConfig reads the configuration file at m.ConfigPath.
+func (m *Main) loadConfig() error {
 	// The expand() function is here to automatically expand "~" to the user's
 	// home directory. This is a common task as configuration files are typing
 	// under the home directory during local development.
@@ -183,32 +265,74 @@ func ReadConfigFile(filename string) (Config, error) {
 }
 
 func (m *Main) Run(ctx context.Context) (err error) {
+	if err := m.setup(); err != nil {
+		return err
+	}
+
+	if err := m.Discord.Open(ctx); err != nil {
+		return err
+	}
+
+	slog.Log(ctx, slog.LevelInfo, "ctfbotd started")
+
+	return nil
+}
+
+// setup opens the database, and configures the Discord server with the
+// services backed by it.
+func (m *Main) setup() (err error) {
 	// Expand the DSN (in case it is in the user home directory ("~")).
 	// Then open the database. This will instantiate the SQLite connection
 	// and execute any pending migration files.
 	if m.DB.DSN, err = expandDSN(m.Config.DB.DSN); err != nil {
 		return fmt.Errorf("cannot expand dsn: %w", err)
 	}
+	if m.DB.EncryptionKey, err = base64.StdEncoding.DecodeString(m.Config.DB.EncryptionKey); err != nil {
+		return fmt.Errorf("cannot decode encryption key: %w", err)
+	}
 	if err := m.DB.Open(); err != nil {
 		return fmt.Errorf("cannot open db: %w", err)
 	}
 
-	ctfTimeClient := ctftime.NewClient()
+	m.CTFTime.TTL = m.Config.CTFTime.CacheTTL
+	m.CTFTime.StaleTTL = m.Config.CTFTime.CacheStaleTTL
+	if m.Config.CTFTime.PersistCache {
+		m.CTFTime.Store = sqlite.NewCacheService(m.DB)
+	}
+
 	ctfService := sqlite.NewCTFService(m.DB)
+	challengeService := sqlite.NewChallengeService(m.DB)
+	solveService := sqlite.NewSolveService(m.DB)
+	claimService := sqlite.NewClaimService(m.DB)
+	credentialsService := sqlite.NewCredentialsService(m.DB)
+	snapshotService := sqlite.NewSnapshotService(m.DB)
+	reminderService := sqlite.NewReminderService(m.DB)
+	pollService := sqlite.NewPollService(m.DB)
+	availabilityService := sqlite.NewAvailabilityService(m.DB)
 
 	m.Discord.BotToken = m.Config.Discord.BotToken
 	m.Discord.GuildID = m.Config.Discord.GuildID
 	m.Discord.RegistrationChannel = m.Config.Discord.RegistrationChannel
 	m.Discord.GeneralChannel = m.Config.Discord.GeneralChannel
+	m.Discord.ArchiveCategory = m.Config.Discord.ArchiveCategory
+	m.Discord.MessageContent = m.Config.Discord.MessageContent
+	m.Discord.SyncInterval = m.Config.Discord.SyncInterval
+	m.Discord.ScoreboardInterval = m.Config.Discord.ScoreboardInterval
+	m.Discord.ReconcileInterval = m.Config.Discord.ReconcileInterval
+	m.Discord.ReminderOffsets = m.Config.Discord.ReminderOffsets
+	m.Discord.CloseAfter = m.Config.Discord.CloseAfter
+	m.Discord.ArchiveAfter = m.Config.Discord.ArchiveAfter
 
 	m.Discord.CTFService = ctfService
-	m.Discord.CTFTimeClient = ctfTimeClient
-
-	if err := m.Discord.Open(ctx); err != nil {
-		return err
-	}
-
-	slog.Log(ctx, slog.LevelInfo, "ctfbotd started")
+	m.Discord.ChallengeService = challengeService
+	m.Discord.SolveService = solveService
+	m.Discord.ClaimService = claimService
+	m.Discord.CredentialsService = credentialsService
+	m.Discord.SnapshotService = snapshotService
+	m.Discord.ReminderService = reminderService
+	m.Discord.PollService = pollService
+	m.Discord.AvailabilityService = availabilityService
+	m.Discord.CTFTimeClient = m.CTFTime
 
 	return nil
 }
@@ -0,0 +1,66 @@
+package ctfbot
+
+import (
+	"context"
+	"time"
+)
+
+// Credentials represent how the team logs in to the platform of a CTF.
+type Credentials struct {
+	ID    int
+	CTFID int
+
+	// Scoring platform, one of the Platform constants. Empty if the bot
+	// can't talk to it.
+	Platform string
+	URL      string
+	TeamName string
+
+	// Secrets, encrypted at rest. Token is either a CTFd access token
+	// or a rCTF team token.
+	Token    string
+	Password string
+
+	// Metadata about creation.
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (c *Credentials) Validate() error {
+	if c.CTFID == 0 {
+		return Errorf(EINVALID, "CTF required.")
+	}
+
+	if c.URL == "" {
+		return Errorf(EINVALID, "URL required.")
+	}
+
+	switch c.Platform {
+	case "", PlatformCTFd, PlatformRCTF:
+	default:
+		return Errorf(EINVALID, "Unsupported platform `%s`.", c.Platform)
+	}
+
+	return nil
+}
+
+type CredentialsService interface {
+	// Sets the credentials of a CTF, replacing the existing ones.
+	SetCredentials(ctx context.Context, creds *Credentials) error
+
+	// Retrieves the credentials of a CTF.
+	FindCredentialsByCTFID(ctx context.Context, ctfID int) (*Credentials, error)
+
+	// Retrieves a list of credentials by filter.
+	FindCredentials(ctx context.Context, filter CredentialsFilter) ([]*Credentials, int, error)
+}
+
+// CredentialsFilter represents a filter passed to FindCredentials().
+type CredentialsFilter struct {
+	CTFID    *int
+	Platform *string
+
+	// Limit and offset.
+	Limit  int
+	Offset int
+}
@@ -5,17 +5,56 @@ import (
 	"time"
 )
 
+// CTF statuses.
+const (
+	CTFStatusActive   = "active"
+	CTFStatusArchived = "archived"
+
+	// The CTF is being deleted, what's left of it on Discord has to go.
+	CTFStatusDeleting = "deleting"
+)
+
 type CTF struct {
-	ID    int
-	Name  string
-	Start time.Time
+	ID     int
+	Name   string
+	Start  time.Time
+	Finish time.Time
 
 	// Discord-related information.
 	RoleID  string
 	CanJoin bool
 
+	// Channels created along with the CTF, and the message players join
+	// with. Empty for CTFs created before they were stored.
+	CategoryID            string
+	RegistrationChannelID string
+	GeneralChannelID      string
+	RegistrationMessageID string
+
+	// One of CTFStatusActive, CTFStatusArchived or CTFStatusDeleting.
+	// Defaults to active.
+	Status string
+
+	// Pinned message listing the status of every challenge.
+	BoardMessageID string
+
+	// Channels moved to the archive along with the CTF, brought back when
+	// it's unarchived. Empty for CTFs archived before they were stored.
+	ArchivedChannelIDs []string
+
+	// When registrations are closed, and the CTF archived, automatically.
+	// Zero if not scheduled.
+	CloseAt   time.Time
+	ArchiveAt time.Time
+
 	// CTFTime infos.
+	CTFTimeID  int
 	CTFTimeURL string
+	Weight     float64
+	Format     string
+
+	// Official website of the CTF.
+	URL string
 
 	// Metadata about creation.
 	CreatedAt time.Time
@@ -31,6 +70,12 @@ func (c *CTF) Validate() error {
 		return Errorf(EINVALID, "Player role required.")
 	}
 
+	switch c.Status {
+	case CTFStatusActive, CTFStatusArchived, CTFStatusDeleting:
+	default:
+		return Errorf(EINVALID, "Unknown CTF status `%s`.", c.Status)
+	}
+
 	return nil
 }
 
@@ -41,9 +86,20 @@ type CTFService interface {
 	// Retrieves a CTF by name.
 	FindCTFByName(ctx context.Context, name string) (*CTF, error)
 
+	// Retrieves a CTF by the ID of its category channel.
+	FindCTFByCategoryID(ctx context.Context, categoryID string) (*CTF, error)
+
 	// Retrieves a list of ctfs by filter.
 	FindCTFs(ctx context.Context, filter CTFFilter) ([]*CTF, int, error)
 
+	// Retrieves the active CTFs whose registrations are due to be closed,
+	// according to the clock of the service.
+	FindCTFsToClose(ctx context.Context) ([]*CTF, error)
+
+	// Retrieves the active CTFs that are due to be archived, according to
+	// the clock of the service.
+	FindCTFsToArchive(ctx context.Context) ([]*CTF, error)
+
 	// Updates a CTF object.
 	UpdateCTF(ctx context.Context, name string, upd CTFUpdate) (*CTF, error)
 
@@ -53,10 +109,18 @@ type CTFService interface {
 
 // CTFFilter represents a filter passed to FindCTFs().
 type CTFFilter struct {
-	ID      *int
-	Name    *string
-	RoleID  *string
-	CanJoin *bool
+	ID         *int
+	Name       *string
+	RoleID     *string
+	CanJoin    *bool
+	Status     *string
+	CTFTimeID  *int
+	CategoryID *string
+
+	// Only CTFs scheduled to close their registrations, or to be archived,
+	// by this time.
+	CloseBy   *time.Time
+	ArchiveBy *time.Time
 
 	// Limit and offset.
 	Limit  int
@@ -65,9 +129,23 @@ type CTFFilter struct {
 
 // CTFUpdate represents a filter passed to UpdateCTF().
 type CTFUpdate struct {
-	Name       *string
-	RoleID     *string
-	CanJoin    *bool
-	CTFTimeURL *string
-	Start      *time.Time
+	Name                  *string
+	RoleID                *string
+	CategoryID            *string
+	RegistrationChannelID *string
+	GeneralChannelID      *string
+	RegistrationMessageID *string
+	CanJoin               *bool
+	Status                *string
+	BoardMessageID        *string
+	ArchivedChannelIDs    *[]string
+	CTFTimeID             *int
+	CTFTimeURL            *string
+	Weight                *float64
+	Format                *string
+	URL                   *string
+	Start                 *time.Time
+	Finish                *time.Time
+	CloseAt               *time.Time
+	ArchiveAt             *time.Time
 }
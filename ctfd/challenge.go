@@ -0,0 +1,49 @@
+package ctfd
+
+import "time"
+
+type Challenge struct {
+	ID         int    `json:"id"`
+	Type       string `json:"type"`
+	Name       string `json:"name"`
+	Value      int    `json:"value"`
+	Solves     int    `json:"solves"`
+	SolvedByMe bool   `json:"solved_by_me"`
+	Category   string `json:"category"`
+	Tags       []Tag  `json:"tags"`
+}
+
+type Tag struct {
+	Value string `json:"value"`
+}
+
+type Solve struct {
+	ChallengeID int       `json:"challenge_id"`
+	Challenge   Challenge `json:"challenge"`
+	User        Account   `json:"user"`
+	Date        time.Time `json:"date"`
+}
+
+// Account represents either a user or a team, depending on the CTFd mode.
+type Account struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+
+	// Ordinal position on the scoreboard, e.g. "3rd". Empty when hidden.
+	Place string `json:"place"`
+}
+
+// ScoreboardEntry represents a user or a team on the scoreboard.
+type ScoreboardEntry struct {
+	Position  int    `json:"pos"`
+	AccountID int    `json:"account_id"`
+	Name      string `json:"name"`
+	Score     int    `json:"score"`
+}
+
+// response is the envelope wrapping every CTFd API response.
+type response struct {
+	Success bool `json:"success"`
+	Data    any  `json:"data"`
+}
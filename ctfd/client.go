@@ -0,0 +1,182 @@
+package ctfd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/havce/ctfbot"
+)
+
+// Client talks to the REST API of a CTFd instance. Requests are authenticated
+// either with an access token or, if the token is not set, with the session
+// cookie of a logged in user.
+type Client struct {
+	c *http.Client
+
+	// Base URL of the CTFd instance, e.g. https://demo.ctfd.io.
+	URL string
+
+	// Access token generated from the CTFd user settings.
+	Token string
+
+	// Value of the "session" cookie of a logged in user.
+	Session string
+}
+
+func NewClient(baseURL string) *Client {
+	return &Client{
+		c:   http.DefaultClient,
+		URL: baseURL,
+	}
+}
+
+// Ensure client implements the platform interface.
+var _ ctfbot.Platform = (*Client)(nil)
+
+func (c *Client) FindChallenges(ctx context.Context) ([]*ctfbot.PlatformChallenge, error) {
+	challenges := make([]*Challenge, 0)
+	if err := c.get(ctx, "/api/v1/challenges", &challenges); err != nil {
+		return nil, err
+	}
+
+	chals := make([]*ctfbot.PlatformChallenge, 0, len(challenges))
+	for _, challenge := range challenges {
+		chals = append(chals, &ctfbot.PlatformChallenge{
+			ID:       strconv.Itoa(challenge.ID),
+			Name:     challenge.Name,
+			Category: challenge.Category,
+			Points:   challenge.Value,
+			Solves:   challenge.Solves,
+		})
+	}
+	return chals, nil
+}
+
+func (c *Client) FindSolves(ctx context.Context) ([]*ctfbot.PlatformSolve, error) {
+	solves := make([]*Solve, 0)
+	if err := c.getMe(ctx, "solves", &solves); err != nil {
+		return nil, err
+	}
+
+	platformSolves := make([]*ctfbot.PlatformSolve, 0, len(solves))
+	for _, solve := range solves {
+		platformSolves = append(platformSolves, &ctfbot.PlatformSolve{
+			ChallengeID:   strconv.Itoa(solve.ChallengeID),
+			ChallengeName: solve.Challenge.Name,
+			UserName:      solve.User.Name,
+			SolvedAt:      solve.Date,
+		})
+	}
+	return platformSolves, nil
+}
+
+func (c *Client) FindStanding(ctx context.Context) (*ctfbot.Standing, error) {
+	var me Account
+	if err := c.getMe(ctx, "", &me); err != nil {
+		return nil, err
+	}
+
+	return &ctfbot.Standing{
+		TeamName: me.Name,
+		Position: parsePlace(me.Place),
+		Score:    me.Score,
+	}, nil
+}
+
+func (c *Client) FindScoreboard(ctx context.Context, limit int) ([]*ctfbot.Standing, error) {
+	entries := make([]*ScoreboardEntry, 0)
+	if err := c.get(ctx, "/api/v1/scoreboard", &entries); err != nil {
+		return nil, err
+	}
+
+	// CTFd always returns the whole scoreboard.
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	standings := make([]*ctfbot.Standing, 0, len(entries))
+	for _, entry := range entries {
+		standings = append(standings, &ctfbot.Standing{
+			TeamName: entry.Name,
+			Position: entry.Position,
+			Score:    entry.Score,
+		})
+	}
+	return standings, nil
+}
+
+// getMe requests a resource of our own team. CTFs running in user mode have
+// no teams and answer 404, so it falls back to the resource of the current
+// user.
+func (c *Client) getMe(ctx context.Context, resource string, v any) error {
+	err := c.get(ctx, path.Join("/api/v1/teams/me", resource), v)
+	if ctfbot.ErrorCode(err) != ctfbot.ENOTFOUND {
+		return err
+	}
+	return c.get(ctx, path.Join("/api/v1/users/me", resource), v)
+}
+
+// parsePlace converts an ordinal like "3rd" to 3. Returns 0 if the place is
+// unknown.
+func parsePlace(place string) int {
+	digits := strings.TrimRightFunc(place, func(r rune) bool {
+		return !unicode.IsDigit(r)
+	})
+	n, _ := strconv.Atoi(digits)
+	return n
+}
+
+// get performs an authenticated GET request to path and decodes the data of
+// the response into v.
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return err
+	}
+
+	u = u.JoinPath(path)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Token "+c.Token)
+	} else if c.Session != "" {
+		req.AddCookie(&http.Cookie{Name: "session", Value: c.Session})
+	}
+
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return ctfbot.Errorf(ctfbot.EUNAUTHORIZED, "CTFd refused our credentials.")
+	} else if resp.StatusCode == http.StatusNotFound {
+		return ctfbot.Errorf(ctfbot.ENOTFOUND, "CTFd resource not found.")
+	} else if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return ctfbot.Errorf(ctfbot.EINTERNAL, "CTFd returned status %d.", resp.StatusCode)
+	}
+
+	r := response{Data: v}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		// CTFd answers with the HTML login page when the session is not valid.
+		return ctfbot.Errorf(ctfbot.EINVALID, "Couldn't understand the CTFd response. Are the URL and credentials right?")
+	}
+
+	if !r.Success {
+		return ctfbot.Errorf(ctfbot.EINVALID, "CTFd request was not successful.")
+	}
+
+	return nil
+}
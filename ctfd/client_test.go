@@ -0,0 +1,203 @@
+package ctfd_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/havce/ctfbot"
+	"github.com/havce/ctfbot/ctfd"
+	"github.com/havce/ctfbot/ctfd/ctfdtest"
+)
+
+func TestClient_FindChallenges(t *testing.T) {
+	s := ctfdtest.NewServer("token", "session")
+	defer s.Close()
+
+	s.Challenges = []ctfd.Challenge{
+		{ID: 1, Name: "baby pwn", Category: "Pwn", Value: 100, Solves: 42},
+		{ID: 7, Name: "rsa", Category: "Crypto", Value: 500},
+	}
+
+	c := ctfd.NewClient(s.URL)
+	c.Token = "token"
+
+	chals, err := c.FindChallenges(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	} else if got, want := len(chals), 2; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	}
+
+	if got, want := *chals[0], (ctfbot.PlatformChallenge{ID: "1", Name: "baby pwn", Category: "Pwn", Points: 100, Solves: 42}); got != want {
+		t.Fatalf("challenge=%+v, want %+v", got, want)
+	}
+	if got, want := chals[1].ID, "7"; got != want {
+		t.Fatalf("ID=%q, want %q", got, want)
+	}
+}
+
+func TestClient_Auth(t *testing.T) {
+	s := ctfdtest.NewServer("token", "session")
+	defer s.Close()
+
+	t.Run("Token", func(t *testing.T) {
+		c := ctfd.NewClient(s.URL)
+		c.Token = "token"
+		if _, err := c.FindChallenges(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Session", func(t *testing.T) {
+		c := ctfd.NewClient(s.URL)
+		c.Session = "session"
+		if _, err := c.FindChallenges(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure the token is preferred to the session.
+	t.Run("TokenOverSession", func(t *testing.T) {
+		c := ctfd.NewClient(s.URL)
+		c.Token, c.Session = "bad", "session"
+		if _, err := c.FindChallenges(context.Background()); ctfbot.ErrorCode(err) != ctfbot.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("ErrBadToken", func(t *testing.T) {
+		c := ctfd.NewClient(s.URL)
+		c.Token = "bad"
+		if _, err := c.FindChallenges(context.Background()); ctfbot.ErrorCode(err) != ctfbot.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// CTFd answers with the login page when the session isn't valid.
+	t.Run("ErrBadSession", func(t *testing.T) {
+		c := ctfd.NewClient(s.URL)
+		c.Session = "bad"
+		if _, err := c.FindChallenges(context.Background()); ctfbot.ErrorCode(err) != ctfbot.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestClient_FindSolves(t *testing.T) {
+	solvedAt := time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC)
+
+	for _, userMode := range []bool{false, true} {
+		name := "TeamMode"
+		if userMode {
+			name = "UserMode"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			s := ctfdtest.NewServer("token", "session")
+			defer s.Close()
+
+			s.UserMode = userMode
+			s.Solves = []ctfd.Solve{{
+				ChallengeID: 7,
+				Challenge:   ctfd.Challenge{ID: 7, Name: "rsa"},
+				User:        ctfd.Account{Name: "alice"},
+				Date:        solvedAt,
+			}}
+
+			c := ctfd.NewClient(s.URL)
+			c.Token = "token"
+
+			solves, err := c.FindSolves(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			} else if got, want := len(solves), 1; got != want {
+				t.Fatalf("len=%d, want %d", got, want)
+			}
+
+			want := ctfbot.PlatformSolve{ChallengeID: "7", ChallengeName: "rsa", UserName: "alice", SolvedAt: solvedAt}
+			if got := *solves[0]; got != want {
+				t.Fatalf("solve=%+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestClient_FindStanding(t *testing.T) {
+	t.Run("TeamMode", func(t *testing.T) {
+		s := ctfdtest.NewServer("token", "session")
+		defer s.Close()
+
+		s.Account = ctfd.Account{Name: "havce", Score: 1337, Place: "3rd"}
+
+		c := ctfd.NewClient(s.URL)
+		c.Token = "token"
+
+		standing, err := c.FindStanding(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := *standing, (ctfbot.Standing{TeamName: "havce", Position: 3, Score: 1337}); got != want {
+			t.Fatalf("standing=%+v, want %+v", got, want)
+		}
+	})
+
+	// Ensure the user is used when the CTF has no teams.
+	t.Run("UserMode", func(t *testing.T) {
+		s := ctfdtest.NewServer("token", "session")
+		defer s.Close()
+
+		s.UserMode = true
+		s.Account = ctfd.Account{Name: "alice", Score: 200, Place: "21st"}
+
+		c := ctfd.NewClient(s.URL)
+		c.Token = "token"
+
+		standing, err := c.FindStanding(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := *standing, (ctfbot.Standing{TeamName: "alice", Position: 21, Score: 200}); got != want {
+			t.Fatalf("standing=%+v, want %+v", got, want)
+		}
+	})
+
+	// Ensure the user isn't mistaken for the team when asking for the team
+	// fails for another reason.
+	t.Run("ErrTeamUnavailable", func(t *testing.T) {
+		s := ctfdtest.NewServer("token", "session")
+		defer s.Close()
+
+		s.Account = ctfd.Account{Name: "alice"}
+		s.Failures["/api/v1/teams/me"] = http.StatusInternalServerError
+
+		c := ctfd.NewClient(s.URL)
+		c.Token = "token"
+
+		if _, err := c.FindStanding(context.Background()); ctfbot.ErrorCode(err) != ctfbot.EINTERNAL {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestClient_FindScoreboard(t *testing.T) {
+	s := ctfdtest.NewServer("token", "session")
+	defer s.Close()
+
+	s.Scoreboard = []ctfd.ScoreboardEntry{
+		{Position: 1, Name: "first", Score: 300},
+		{Position: 2, Name: "second", Score: 200},
+		{Position: 3, Name: "third", Score: 100},
+	}
+
+	// The scoreboard is public.
+	c := ctfd.NewClient(s.URL)
+
+	standings, err := c.FindScoreboard(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	} else if got, want := len(standings), 2; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	} else if got, want := *standings[1], (ctfbot.Standing{TeamName: "second", Position: 2, Score: 200}); got != want {
+		t.Fatalf("standing=%+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,152 @@
+// Package ctfdtest provides a fake CTFd instance for testing clients.
+package ctfdtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/havce/ctfbot/ctfd"
+)
+
+// Server is a fake CTFd instance backed by an httptest.Server. Set its fields
+// to control the responses.
+type Server struct {
+	*httptest.Server
+
+	mu sync.Mutex
+
+	// Access token and session cookie accepted by the server.
+	Token   string
+	Session string
+
+	// Whether the CTF runs in user mode, where teams don't exist.
+	UserMode bool
+
+	// Our own team, or user in user mode, and its solves.
+	Account ctfd.Account
+	Solves  []ctfd.Solve
+
+	Challenges []ctfd.Challenge
+	Scoreboard []ctfd.ScoreboardEntry
+
+	// Status codes answered instead of the resources, by path.
+	Failures map[string]int
+}
+
+// NewServer starts a fake CTFd instance in team mode, accepting token and
+// session. The caller should call Close when finished, to shut it down.
+func NewServer(token, session string) *Server {
+	s := &Server{
+		Token:    token,
+		Session:  session,
+		Failures: make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/challenges", s.authenticated(s.handleChallenges))
+	mux.HandleFunc("GET /api/v1/teams/me", s.authenticated(s.teamsOnly(s.handleMe)))
+	mux.HandleFunc("GET /api/v1/teams/me/solves", s.authenticated(s.teamsOnly(s.handleSolves)))
+	mux.HandleFunc("GET /api/v1/users/me", s.authenticated(s.handleMe))
+	mux.HandleFunc("GET /api/v1/users/me/solves", s.authenticated(s.handleSolves))
+	mux.HandleFunc("GET /api/v1/scoreboard", s.handleScoreboard)
+
+	s.Server = httptest.NewServer(s.failing(mux))
+	return s
+}
+
+func (s *Server) handleChallenges(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeResponse(w, http.StatusOK, s.Challenges)
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeResponse(w, http.StatusOK, s.Account)
+}
+
+func (s *Server) handleSolves(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeResponse(w, http.StatusOK, s.Solves)
+}
+
+func (s *Server) handleScoreboard(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeResponse(w, http.StatusOK, s.Scoreboard)
+}
+
+// authenticated rejects requests without the token or the session cookie.
+// Like CTFd, a wrong token is refused while a missing session gets the login
+// page.
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		token, session := s.Token, s.Session
+		s.mu.Unlock()
+
+		if header := r.Header.Get("Authorization"); header != "" {
+			if strings.TrimPrefix(header, "Token ") != token {
+				writeResponse(w, http.StatusForbidden, nil)
+				return
+			}
+			next(w, r)
+			return
+		}
+
+		if cookie, err := r.Cookie("session"); err != nil || cookie.Value != session {
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html><body>Login</body></html>"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+// teamsOnly answers 404 in user mode, like CTFd does for the team endpoints.
+func (s *Server) teamsOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		userMode := s.UserMode
+		s.mu.Unlock()
+
+		if userMode {
+			writeResponse(w, http.StatusNotFound, nil)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// failing answers the status codes set in Failures.
+func (s *Server) failing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		status, ok := s.Failures[r.URL.Path]
+		s.mu.Unlock()
+
+		if ok {
+			writeResponse(w, status, nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeResponse(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"success": status >= 200 && status < 300,
+		"data":    data,
+	})
+}
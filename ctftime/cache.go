@@ -0,0 +1,246 @@
+package ctftime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/havce/ctfbot"
+)
+
+const (
+	// How long responses are fresh, unless told otherwise.
+	DefaultCacheTTL = 10 * time.Minute
+
+	// How long stale responses are served while being fetched again in the
+	// background, unless told otherwise.
+	DefaultCacheStaleTTL = time.Hour
+
+	// How long responses are kept to be served when CTFTime is down, unless
+	// told otherwise.
+	DefaultCacheRetention = 7 * 24 * time.Hour
+
+	// Time windows of the queries are rounded to this, so that queries made
+	// around the same time share their response.
+	cacheWindow = time.Hour
+
+	// How long fetching a stale response in the background may take.
+	revalidateTimeout = 30 * time.Second
+)
+
+// Cache is an EventService answering from the responses of another one,
+// kept in memory and, optionally, in a CacheService to survive restarts.
+//
+// Fresh responses are served as they are. Stale ones are served while they're
+// fetched again in the background. Older ones are fetched again right away,
+// and only served if CTFTime can't be reached.
+type Cache struct {
+	service EventService
+
+	// Optional, keeps the responses across restarts.
+	Store ctfbot.CacheService
+
+	// How long responses are fresh, how long after that they're served while
+	// being fetched again, and how long they're kept around at all.
+	TTL       time.Duration
+	StaleTTL  time.Duration
+	Retention time.Duration
+
+	Logger *slog.Logger
+
+	// Returns the current time. Defaults to time.Now().
+	Now func() time.Time
+
+	mu         sync.Mutex
+	entries    map[string]*ctfbot.CacheEntry
+	refreshing map[string]bool
+
+	// Background revalidations, stopped on Close.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Ensure cache implements the event service interface.
+var _ EventService = (*Cache)(nil)
+
+func NewCache(service EventService) *Cache {
+	c := &Cache{
+		service:    service,
+		TTL:        DefaultCacheTTL,
+		StaleTTL:   DefaultCacheStaleTTL,
+		Retention:  DefaultCacheRetention,
+		Logger:     slog.Default(),
+		Now:        time.Now,
+		entries:    make(map[string]*ctfbot.CacheEntry),
+		refreshing: make(map[string]bool),
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	return c
+}
+
+// Close cancels the responses being fetched in the background, and waits for
+// them to finish.
+func (c *Cache) Close() error {
+	c.cancel()
+	c.wg.Wait()
+	return nil
+}
+
+func (c *Cache) FindEventByID(ctx context.Context, id int) (*Event, error) {
+	event := &Event{}
+	err := c.fetch(ctx, fmt.Sprintf("ctftime/event/%d", id), event, func(ctx context.Context) (any, error) {
+		return c.service.FindEventByID(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (c *Cache) FindEvents(ctx context.Context, filter EventFilter) ([]*Event, int, error) {
+	// Ask for as many events as CTFTime gives in the time window, the other
+	// filters are applied to the cached response.
+	window := EventFilter{Limit: MaxEventsLimit}
+	key := "ctftime/events"
+
+	if filter.Start != nil {
+		start := filter.Start.Truncate(cacheWindow)
+		window.Start = &start
+		key += fmt.Sprintf("/start=%d", start.Unix())
+	}
+
+	if filter.Finish != nil {
+		finish := filter.Finish.Add(cacheWindow - 1).Truncate(cacheWindow)
+		window.Finish = &finish
+		key += fmt.Sprintf("/finish=%d", finish.Unix())
+	}
+
+	events := []*Event{}
+	err := c.fetch(ctx, key, &events, func(ctx context.Context) (any, error) {
+		events, _, err := c.service.FindEvents(ctx, window)
+		return events, err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return filter.apply(events), len(events), nil
+}
+
+// fetch decodes into v the response stored under key, calling fn to fetch it
+// when there's none or it's stale.
+func (c *Cache) fetch(ctx context.Context, key string, v any, fn func(ctx context.Context) (any, error)) error {
+	entry := c.lookup(ctx, key)
+	if entry != nil {
+		age := c.Now().Sub(entry.FetchedAt)
+		if age < c.TTL {
+			return json.Unmarshal(entry.Value, v)
+		} else if age < c.TTL+c.StaleTTL {
+			c.revalidate(key, fn)
+			return json.Unmarshal(entry.Value, v)
+		}
+	}
+
+	value, err := c.refresh(ctx, key, fn)
+	if err != nil {
+		// An old answer is better than none when CTFTime is down, but not
+		// when it says the event doesn't exist.
+		if entry != nil && ctfbot.ErrorCode(err) != ctfbot.ENOTFOUND {
+			c.Logger.Warn("Serving old CTFTime response", "key", key, "fetched_at", entry.FetchedAt, "err", err)
+			return json.Unmarshal(entry.Value, v)
+		}
+		return err
+	}
+	return json.Unmarshal(value, v)
+}
+
+// lookup returns the response stored under key, or nil if there's none.
+func (c *Cache) lookup(ctx context.Context, key string) *ctfbot.CacheEntry {
+	c.mu.Lock()
+	entry := c.entries[key]
+	c.mu.Unlock()
+
+	if entry != nil || c.Store == nil {
+		return entry
+	}
+
+	entry, err := c.Store.FindCacheEntry(ctx, key)
+	if err != nil {
+		if ctfbot.ErrorCode(err) != ctfbot.ENOTFOUND {
+			c.Logger.Warn("Couldn't read CTFTime cache", "key", key, "err", err)
+		}
+		return nil
+	}
+
+	c.mu.Lock()
+	c.entries[key] = entry
+	c.mu.Unlock()
+	return entry
+}
+
+// refresh fetches the response under key with fn, and stores it.
+func (c *Cache) refresh(ctx context.Context, key string, fn func(ctx context.Context) (any, error)) ([]byte, error) {
+	result, err := fn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	now := c.Now()
+	entry := &ctfbot.CacheEntry{Key: key, Value: value, FetchedAt: now}
+
+	// Forget the responses too old to be served at all.
+	c.mu.Lock()
+	c.entries[key] = entry
+	for k, e := range c.entries {
+		if now.Sub(e.FetchedAt) > c.Retention {
+			delete(c.entries, k)
+		}
+	}
+	c.mu.Unlock()
+
+	if c.Store != nil {
+		if err := c.Store.SetCacheEntry(ctx, entry); err != nil {
+			c.Logger.Warn("Couldn't write CTFTime cache", "key", key, "err", err)
+		} else if err := c.Store.DeleteCacheEntries(ctx, now.Add(-c.Retention)); err != nil {
+			c.Logger.Warn("Couldn't prune CTFTime cache", "err", err)
+		}
+	}
+	return value, nil
+}
+
+// revalidate fetches the response under key again in the background, unless
+// it's already being fetched or the cache is closed.
+func (c *Cache) revalidate(key string, fn func(ctx context.Context) (any, error)) {
+	c.mu.Lock()
+	if c.refreshing[key] || c.ctx.Err() != nil {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[key] = true
+	c.wg.Add(1)
+	c.mu.Unlock()
+
+	go func() {
+		defer c.wg.Done()
+		defer func() {
+			c.mu.Lock()
+			delete(c.refreshing, key)
+			c.mu.Unlock()
+		}()
+
+		ctx, cancel := context.WithTimeout(c.ctx, revalidateTimeout)
+		defer cancel()
+
+		if _, err := c.refresh(ctx, key, fn); err != nil {
+			c.Logger.Warn("Couldn't revalidate CTFTime response", "key", key, "err", err)
+		}
+	}()
+}
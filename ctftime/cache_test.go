@@ -0,0 +1,232 @@
+package ctftime_test
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/havce/ctfbot"
+	"github.com/havce/ctfbot/ctftime"
+)
+
+// Time of the clock of the caches when they're created.
+var epoch = time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC)
+
+// EventService is a fake CTFTime, counting the calls it gets.
+type EventService struct {
+	mu     sync.Mutex
+	events []*ctftime.Event
+	err    error
+	calls  int
+}
+
+func (s *EventService) FindEventByID(ctx context.Context, id int) (*ctftime.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	for _, event := range s.events {
+		if event.ID == id {
+			return event, nil
+		}
+	}
+	return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "Event not found.")
+}
+
+func (s *EventService) FindEvents(ctx context.Context, filter ctftime.EventFilter) ([]*ctftime.Event, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	if s.err != nil {
+		return nil, 0, s.err
+	}
+	return s.events, len(s.events), nil
+}
+
+// Set replaces the events and the error returned from now on.
+func (s *EventService) Set(events []*ctftime.Event, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events, s.err = events, err
+}
+
+// Calls returns how many calls the service got.
+func (s *EventService) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+// NewCache returns a cache of s with a clock moved by the returned function.
+func NewCache(tb testing.TB, s *EventService) (*ctftime.Cache, func(time.Duration)) {
+	tb.Helper()
+
+	var mu sync.Mutex
+	now := epoch
+
+	c := ctftime.NewCache(s)
+	c.Logger = slog.New(slog.DiscardHandler)
+	c.Now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	tb.Cleanup(func() { _ = c.Close() })
+
+	return c, func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+}
+
+// MustFindEvents returns the titles of the events found by FindEvents. Fatal
+// on error.
+func MustFindEvents(tb testing.TB, c *ctftime.Cache) []string {
+	tb.Helper()
+
+	events, err := FindEvents(c)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	titles := []string{}
+	for _, event := range events {
+		titles = append(titles, event.Title)
+	}
+	return titles
+}
+
+// FindEvents returns the events of the week after the cache was created. The
+// window doesn't move with the clock, so that the same response is asked for.
+func FindEvents(c *ctftime.Cache) ([]*ctftime.Event, error) {
+	start := epoch
+	finish := start.Add(7 * 24 * time.Hour)
+	events, _, err := c.FindEvents(context.Background(), ctftime.EventFilter{Start: &start, Finish: &finish})
+	return events, err
+}
+
+func TestCache_FindEvents(t *testing.T) {
+	// The events start far enough that they don't start while the clock is
+	// moved.
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	v1 := []*ctftime.Event{{ID: 1, Title: "havcectf", Start: start}}
+	v2 := []*ctftime.Event{{ID: 1, Title: "havcectf 2024", Start: start}}
+
+	t.Run("Fresh", func(t *testing.T) {
+		s := &EventService{events: v1}
+		c, advance := NewCache(t, s)
+
+		if got := MustFindEvents(t, c); len(got) != 1 || got[0] != "havcectf" {
+			t.Fatalf("titles=%v", got)
+		}
+
+		// Ensure fresh responses don't hit CTFTime again.
+		s.Set(v2, nil)
+		advance(c.TTL - time.Minute)
+		if got := MustFindEvents(t, c); len(got) != 1 || got[0] != "havcectf" {
+			t.Fatalf("titles=%v", got)
+		} else if got, want := s.Calls(), 1; got != want {
+			t.Fatalf("calls=%d, want %d", got, want)
+		}
+	})
+
+	// Ensure stale responses are served while they're fetched again.
+	t.Run("Stale", func(t *testing.T) {
+		s := &EventService{events: v1}
+		c, advance := NewCache(t, s)
+		MustFindEvents(t, c)
+
+		s.Set(v2, nil)
+		advance(c.TTL + time.Minute)
+		if got := MustFindEvents(t, c); len(got) != 1 || got[0] != "havcectf" {
+			t.Fatalf("titles=%v", got)
+		}
+
+		// Wait for the revalidation, the new response is fresh.
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		} else if got, want := s.Calls(), 2; got != want {
+			t.Fatalf("calls=%d, want %d", got, want)
+		}
+		if got := MustFindEvents(t, c); len(got) != 1 || got[0] != "havcectf 2024" {
+			t.Fatalf("titles=%v", got)
+		} else if got, want := s.Calls(), 2; got != want {
+			t.Fatalf("calls=%d, want %d", got, want)
+		}
+	})
+
+	// Ensure old responses are served when CTFTime is rate limited.
+	t.Run("RateLimited", func(t *testing.T) {
+		s := &EventService{events: v1}
+		c, advance := NewCache(t, s)
+		MustFindEvents(t, c)
+
+		s.Set(nil, ctfbot.Errorf(ctfbot.EINTERNAL, "CTFTime is unavailable, it returned status 429."))
+		advance(c.TTL + c.StaleTTL + time.Minute)
+		if got := MustFindEvents(t, c); len(got) != 1 || got[0] != "havcectf" {
+			t.Fatalf("titles=%v", got)
+		} else if got, want := s.Calls(), 2; got != want {
+			t.Fatalf("calls=%d, want %d", got, want)
+		}
+	})
+
+	// Ensure responses older than the retention aren't served anymore.
+	t.Run("ErrRetention", func(t *testing.T) {
+		s := &EventService{events: v1}
+		c, advance := NewCache(t, s)
+		MustFindEvents(t, c)
+
+		// Another response evicts the old one.
+		advance(c.Retention + time.Hour)
+		if _, err := c.FindEventByID(context.Background(), 1); err != nil {
+			t.Fatal(err)
+		}
+
+		s.Set(nil, ctfbot.Errorf(ctfbot.EINTERNAL, "CTFTime is unavailable, it returned status 429."))
+		if _, err := FindEvents(c); ctfbot.ErrorCode(err) != ctfbot.EINTERNAL {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure the events CTFTime gave for the whole window are counted.
+	t.Run("Count", func(t *testing.T) {
+		events := make([]*ctftime.Event, ctftime.MaxEventsLimit)
+		for i := range events {
+			events[i] = &ctftime.Event{ID: i, Start: start}
+		}
+
+		c, _ := NewCache(t, &EventService{events: events})
+		start, finish := c.Now(), c.Now().Add(7*24*time.Hour)
+		if events, n, err := c.FindEvents(context.Background(), ctftime.EventFilter{Start: &start, Finish: &finish, Limit: 5}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(events), 5; got != want {
+			t.Fatalf("len=%d, want %d", got, want)
+		} else if got, want := n, ctftime.MaxEventsLimit; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		}
+	})
+}
+
+func TestCache_FindEventByID(t *testing.T) {
+	// Ensure CTFTime saying the event is gone wins over an old response.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		s := &EventService{events: []*ctftime.Event{{ID: 1, Title: "havcectf"}}}
+		c, advance := NewCache(t, s)
+		if _, err := c.FindEventByID(context.Background(), 1); err != nil {
+			t.Fatal(err)
+		}
+
+		s.Set(nil, nil)
+		advance(c.TTL + c.StaleTTL + time.Minute)
+		if _, err := c.FindEventByID(context.Background(), 1); ctfbot.ErrorCode(err) != ctfbot.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
@@ -10,10 +10,17 @@ import (
 	"github.com/havce/ctfbot"
 )
 
+// Most events CTFTime returns at once. Filters other than the time window
+// only apply to these.
+const MaxEventsLimit = 100
+
 type Client struct {
 	c *http.Client
 }
 
+// Ensure client implements the event service interface.
+var _ EventService = (*Client)(nil)
+
 func NewClient() *Client {
 	return &Client{
 		c: http.DefaultClient,
@@ -28,7 +35,7 @@ func (c *Client) FindEventByID(ctx context.Context, id int) (*Event, error) {
 
 	u = u.JoinPath(strconv.Itoa(id), "/")
 
-	req, err := http.NewRequest("GET", u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -42,10 +49,10 @@ func (c *Client) FindEventByID(ctx context.Context, id int) (*Event, error) {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+	if resp.StatusCode == http.StatusNotFound {
 		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "Event not found.")
-	} else if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, ctfbot.Errorf(ctfbot.EINVALID, "Internal server error.")
+	} else if err := statusError(resp); err != nil {
+		return nil, err
 	}
 
 	event := &Event{}
@@ -56,16 +63,23 @@ func (c *Client) FindEventByID(ctx context.Context, id int) (*Event, error) {
 	return event, err
 }
 
-func (c *Client) FindEvents(ctx context.Context, filter EventFilter) ([]*Event, error) {
+func (c *Client) FindEvents(ctx context.Context, filter EventFilter) ([]*Event, int, error) {
 	u, err := url.Parse("https://ctftime.org/api/v1/events/")
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	q := u.Query()
 
-	if filter.Limit != 0 {
-		q.Set("limit", strconv.Itoa(filter.Limit))
+	// CTFTime only filters events by time, and doesn't know about offsets.
+	// Fetch enough events to filter and skip them here.
+	limit := filter.Offset + filter.Limit
+	if filter.Format != nil || filter.OnSite != nil || filter.MinWeight != nil || filter.Restrictions != nil {
+		limit = MaxEventsLimit
+	}
+
+	if limit != 0 {
+		q.Set("limit", strconv.Itoa(min(limit, MaxEventsLimit)))
 	}
 
 	if filter.Start != nil {
@@ -78,9 +92,9 @@ func (c *Client) FindEvents(ctx context.Context, filter EventFilter) ([]*Event,
 
 	u.RawQuery = q.Encode()
 
-	req, err := http.NewRequest("GET", u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	// Workaround for CTFtime API.
@@ -88,14 +102,31 @@ func (c *Client) FindEvents(ctx context.Context, filter EventFilter) ([]*Event,
 
 	resp, err := c.c.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := statusError(resp); err != nil {
+		return nil, 0, err
 	}
 
 	events := make([]*Event, 0)
 	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	defer resp.Body.Close()
 
-	return events, err
+	return filter.apply(events), len(events), nil
+}
+
+// statusError returns the error for an unsuccessful response. CTFTime being
+// rate limited or down is an internal error, so that cached responses are
+// served instead.
+func statusError(resp *http.Response) error {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return ctfbot.Errorf(ctfbot.EINTERNAL, "CTFTime is unavailable, it returned status %d.", resp.StatusCode)
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return ctfbot.Errorf(ctfbot.EINVALID, "CTFTime returned status %d.", resp.StatusCode)
+	}
+	return nil
 }
@@ -1,6 +1,10 @@
 package ctftime
 
-import "time"
+import (
+	"context"
+	"strings"
+	"time"
+)
 
 type Event struct {
 	Organizers    []Team    `json:"organizers"`
@@ -37,9 +41,81 @@ type Team struct {
 	Aliases  []string `json:"aliases"`
 }
 
+// EventService represents a service for finding CTFTime events.
+type EventService interface {
+	// Retrieves an event by ID.
+	FindEventByID(ctx context.Context, id int) (*Event, error)
+
+	// Retrieves a list of events by filter. Also returns how many events
+	// CTFTime gave for the time window before the other filters, which is
+	// never more than MaxEventsLimit.
+	FindEvents(ctx context.Context, filter EventFilter) ([]*Event, int, error)
+}
+
 type EventFilter struct {
 	Start  *time.Time
 	Finish *time.Time
 
-	Limit int
+	// Only events of this format, like "Jeopardy" or "Attack-Defense".
+	Format *string
+
+	// Only onsite events if true, only online ones if false.
+	OnSite *bool
+
+	// Only events weighing at least this much.
+	MinWeight *float64
+
+	// Only events with these restrictions, like "Open" or "Academic".
+	Restrictions *string
+
+	// Limit and offset.
+	Limit  int
+	Offset int
+}
+
+// Match reports whether event passes the filters other than the end of the
+// time window, which CTFTime applies on its own. The start is checked again,
+// as windows are widened to share cached responses.
+func (f *EventFilter) Match(event *Event) bool {
+	if f.Start != nil && event.Start.Before(*f.Start) {
+		return false
+	}
+
+	if f.Format != nil && !strings.EqualFold(event.Format, *f.Format) {
+		return false
+	}
+
+	if f.OnSite != nil && event.OnSite != *f.OnSite {
+		return false
+	}
+
+	if f.MinWeight != nil && event.Weight < *f.MinWeight {
+		return false
+	}
+
+	if f.Restrictions != nil && !strings.EqualFold(event.Restrictions, *f.Restrictions) {
+		return false
+	}
+
+	return true
+}
+
+// apply returns the events matching the filter, skipping the ones before the
+// offset and keeping the limit.
+func (f *EventFilter) apply(events []*Event) []*Event {
+	matching := make([]*Event, 0, len(events))
+	for _, event := range events {
+		if f.Match(event) {
+			matching = append(matching, event)
+		}
+	}
+
+	if f.Offset >= len(matching) {
+		return []*Event{}
+	}
+	matching = matching[f.Offset:]
+	if f.Limit != 0 && len(matching) > f.Limit {
+		matching = matching[:f.Limit]
+	}
+	return matching
 }
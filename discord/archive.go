@@ -0,0 +1,358 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/havce/ctfbot"
+)
+
+const (
+	// Discord doesn't allow more than 50 channels inside a category.
+	maxCategoryChannels = 50
+
+	// Channel names can't be longer than 100 characters.
+	maxChannelNameLength = 100
+
+	// Separates the name of the CTF from the name of its archived channels.
+	// Unlikely to be found in CTF names.
+	archiveSeparator = "・"
+)
+
+func (s *Server) handleArchive(event *handler.CommandEvent) error {
+	keepRole := true
+	if v, ok := event.SlashCommandInteractionData().OptBool("keep_role"); ok {
+		keepRole = v
+	}
+
+	ctf, err := s.ctfFromChannel(event.Channel().ID())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	if err := s.archiveCTF(context.TODO(), ctf, keepRole); err != nil {
+		return Error(event, err)
+	}
+
+	Respond(event, "CTF archived",
+		fmt.Sprintf("`%s` was moved to the archive. Use `/unarchive` to bring it back.", ctf.Name))
+	return nil
+}
+
+func (s *Server) handleUnarchive(event *handler.CommandEvent) error {
+	ctf, err := s.CTFService.FindCTFByName(context.TODO(), event.SlashCommandInteractionData().String("name"))
+	if err != nil {
+		return Error(event, err)
+	}
+
+	if err := s.unarchiveCTF(context.TODO(), ctf); err != nil {
+		return Error(event, err)
+	}
+
+	Respond(event, "CTF unarchived",
+		fmt.Sprintf("`%s` is back. Registrations are closed, use `/open` to open them again.", ctf.Name))
+	return nil
+}
+
+// archiveCTF moves the channels of ctf to the archive, where they are read
+// only for the players. The role of the players is deleted, unless keepRole
+// is set. The CTF is archived as soon as its channels are moved, failing to
+// delete the category or the role afterwards is reported on its own.
+func (s *Server) archiveCTF(ctx context.Context, ctf *ctfbot.CTF, keepRole bool) error {
+	if ctf.Status == ctfbot.CTFStatusArchived {
+		return ctfbot.Errorf(ctfbot.EINVALID, "`%s` is already archived.", ctf.Name)
+	}
+
+	guildID, err := snowflake.Parse(s.GuildID)
+	if err != nil {
+		return err
+	}
+
+	roleID, err := snowflake.Parse(ctf.RoleID)
+	if err != nil {
+		return err
+	}
+
+	category, err := s.categoryChannel(ctf)
+	if err != nil {
+		return err
+	}
+
+	channels := s.childChannels(category.ID())
+	archive, err := s.archiveCategory(guildID, len(channels))
+	if err != nil {
+		return err
+	}
+
+	// Keep track of the moved channels, even if some of them couldn't be.
+	moved, err := s.moveToArchive(ctf, channels, archive.ID(), roleID, keepRole)
+	archivedChannelIDs := append(slices.Clone(ctf.ArchivedChannelIDs), moved...)
+	if err != nil {
+		if _, err := s.CTFService.UpdateCTF(ctx, ctf.Name, ctfbot.CTFUpdate{
+			ArchivedChannelIDs: &archivedChannelIDs,
+		}); err != nil {
+			s.client.Logger().Warn("Couldn't store archived channels", "ctf", ctf.Name, "err", err)
+		}
+		return err
+	}
+
+	// The channels of the CTF are now part of the archive, they're found
+	// again when it's unarchived. Whatever was scheduled doesn't apply to the
+	// archived CTF, nor to it once it's unarchived.
+	status, canJoin, never := ctfbot.CTFStatusArchived, false, time.Time{}
+	categoryID, generalID, registrationID := "", "", ""
+	_, err = s.CTFService.UpdateCTF(ctx, ctf.Name, ctfbot.CTFUpdate{
+		Status:                &status,
+		CanJoin:               &canJoin,
+		CategoryID:            &categoryID,
+		GeneralChannelID:      &generalID,
+		RegistrationChannelID: &registrationID,
+		ArchivedChannelIDs:    &archivedChannelIDs,
+		CloseAt:               &never,
+		ArchiveAt:             &never,
+	})
+	if err != nil {
+		return err
+	}
+
+	// What's left is empty, so the CTF stays archived even if it can't be
+	// cleaned up.
+	var leftovers []string
+	if err := s.client.Rest().DeleteChannel(category.ID()); err != nil {
+		s.client.Logger().Warn("Couldn't delete category of archived CTF", "ctf", ctf.Name, "err", err)
+		leftovers = append(leftovers, "category")
+	}
+
+	if !keepRole {
+		if err := s.client.Rest().DeleteRole(guildID, roleID); err != nil {
+			s.client.Logger().Warn("Couldn't delete role of archived CTF", "ctf", ctf.Name, "err", err)
+			leftovers = append(leftovers, "role")
+		}
+	}
+
+	if len(leftovers) > 0 {
+		return ctfbot.Errorf(ctfbot.EINTERNAL, "`%s` was archived, but its %s couldn't be deleted. Please remove what's left by hand.",
+			ctf.Name, strings.Join(leftovers, " and "))
+	}
+	return nil
+}
+
+// moveToArchive moves channels to the archive category archiveID, where
+// they are read only for the players of ctf if keepRole is set. Returns the
+// IDs of the channels moved, even if it failed half-way.
+func (s *Server) moveToArchive(ctf *ctfbot.CTF, channels []discord.GuildChannel, archiveID, roleID snowflake.ID, keepRole bool) ([]string, error) {
+	// Prefix the channels with the name of the CTF, otherwise we would end
+	// up with a lot of general channels.
+	moved := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		name := truncate(archivedChannelPrefix(ctf)+channel.Name(), maxChannelNameLength)
+		_, err := s.client.Rest().UpdateChannel(channel.ID(), discord.GuildTextChannelUpdate{
+			Name:     &name,
+			ParentID: &archiveID,
+		})
+		if err != nil {
+			return moved, err
+		}
+		moved = append(moved, channel.ID().String())
+
+		if !keepRole {
+			continue
+		}
+
+		allow, deny := discord.Permissions(ReadOnlyChannelPrivileges), discord.PermissionsAll
+		err = s.client.Rest().UpdatePermissionOverwrite(channel.ID(), roleID, discord.RolePermissionOverwriteUpdate{
+			Allow: &allow,
+			Deny:  &deny,
+		})
+		if err != nil {
+			return moved, err
+		}
+	}
+	return moved, nil
+}
+
+// unarchiveCTF moves the channels of ctf out of the archive, into a new
+// category. The role of the players is created again if it was deleted.
+func (s *Server) unarchiveCTF(ctx context.Context, ctf *ctfbot.CTF) error {
+	if ctf.Status != ctfbot.CTFStatusArchived {
+		return ctfbot.Errorf(ctfbot.EINVALID, "`%s` is not archived.", ctf.Name)
+	}
+
+	guildID, err := snowflake.Parse(s.GuildID)
+	if err != nil {
+		return err
+	}
+
+	roleID, err := snowflake.Parse(ctf.RoleID)
+	if err != nil {
+		return err
+	}
+
+	if _, found := s.client.Caches().Role(guildID, roleID); !found {
+		role, err := s.client.Rest().CreateRole(guildID, discord.RoleCreate{
+			Name:        ctf.Name,
+			Mentionable: true,
+		})
+		if err != nil {
+			return err
+		}
+		roleID = role.ID
+	}
+
+	category, err := s.createCTFCategory(guildID, ctf.Name, roleID)
+	if err != nil {
+		return err
+	}
+
+	categoryID := category.ID()
+	upd := ctfbot.CTFUpdate{}
+	for _, channel := range s.archivedChannels(ctf) {
+		name := strings.TrimPrefix(channel.Name(), archivedChannelPrefix(ctf))
+		if name == s.GeneralChannel {
+			generalID := channel.ID().String()
+			upd.GeneralChannelID = &generalID
+		} else if name == s.RegistrationChannel {
+			registrationID := channel.ID().String()
+			upd.RegistrationChannelID = &registrationID
+		}
+
+		_, err := s.client.Rest().UpdateChannel(channel.ID(), discord.GuildTextChannelUpdate{
+			Name:     &name,
+			ParentID: &categoryID,
+		})
+		if err != nil {
+			return err
+		}
+
+		// The registration channel is read only, as it was created.
+		allow, deny := discord.Permissions(DefaultChannelPrivileges), discord.Permissions(0)
+		if name == s.RegistrationChannel {
+			allow, deny = ReadOnlyChannelPrivileges, discord.PermissionsAll
+		}
+		err = s.client.Rest().UpdatePermissionOverwrite(channel.ID(), roleID, discord.RolePermissionOverwriteUpdate{
+			Allow: &allow,
+			Deny:  &deny,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	status, role, parentID, archivedChannelIDs := ctfbot.CTFStatusActive, roleID.String(), categoryID.String(), []string{}
+	upd.Status = &status
+	upd.RoleID = &role
+	upd.CategoryID = &parentID
+	upd.ArchivedChannelIDs = &archivedChannelIDs
+	_, err = s.CTFService.UpdateCTF(ctx, ctf.Name, upd)
+	return err
+}
+
+// archiveCategory returns an archive category with room for n more
+// channels. A new one is created when all of them are full.
+func (s *Server) archiveCategory(guildID snowflake.ID, n int) (discord.GuildChannel, error) {
+	if n > maxCategoryChannels {
+		return nil, ctfbot.Errorf(ctfbot.EINVALID, "Too many channels to archive, there are %d of them.", n)
+	}
+
+	for _, category := range s.archiveCategories() {
+		if len(s.childChannels(category.ID()))+n <= maxCategoryChannels {
+			return category, nil
+		}
+	}
+
+	// Nobody but the players of the archived CTFs can see the archive.
+	return s.client.Rest().CreateGuildChannel(guildID, discord.GuildCategoryChannelCreate{
+		Name: s.ArchiveCategory,
+		PermissionOverwrites: []discord.PermissionOverwrite{
+			discord.RolePermissionOverwrite{
+				RoleID: guildID,
+				Deny:   discord.PermissionsAll,
+			},
+		},
+	})
+}
+
+// archiveCategories returns every archive category.
+func (s *Server) archiveCategories() []discord.GuildChannel {
+	categories := []discord.GuildChannel{}
+	s.client.Caches().ChannelsForEach(func(channel discord.GuildChannel) {
+		if channel.Type() == discord.ChannelTypeGuildCategory && channel.Name() == s.ArchiveCategory {
+			categories = append(categories, channel)
+		}
+	})
+	return categories
+}
+
+// archivedChannels returns the channels of ctf moved to the archive. The ones
+// deleted in the meantime are left out.
+func (s *Server) archivedChannels(ctf *ctfbot.CTF) []discord.GuildChannel {
+	channels := []discord.GuildChannel{}
+	s.client.Caches().ChannelsForEach(func(channel discord.GuildChannel) {
+		channels = append(channels, channel)
+	})
+	return s.archivedChannelsIn(ctf, channels)
+}
+
+// archivedChannelsIn returns the channels of ctf moved to the archive, among
+// channels. It doesn't rely on the cache, so that it also works with the
+// channels fetched through the REST API.
+func (s *Server) archivedChannelsIn(ctf *ctfbot.CTF, channels []discord.GuildChannel) []discord.GuildChannel {
+	byID := make(map[string]discord.GuildChannel, len(channels))
+	archiveIDs := map[snowflake.ID]bool{}
+	for _, channel := range channels {
+		byID[channel.ID().String()] = channel
+		if channel.Type() == discord.ChannelTypeGuildCategory && channel.Name() == s.ArchiveCategory {
+			archiveIDs[channel.ID()] = true
+		}
+	}
+
+	archived := []discord.GuildChannel{}
+	for _, id := range ctf.ArchivedChannelIDs {
+		if channel, found := byID[id]; found {
+			archived = append(archived, channel)
+		}
+	}
+
+	// CTFs archived before the channels were stored can only be found by the
+	// prefix of their channels.
+	if len(ctf.ArchivedChannelIDs) > 0 {
+		return archived
+	}
+
+	for _, channel := range channels {
+		if channel.ParentID() != nil && archiveIDs[*channel.ParentID()] && strings.HasPrefix(channel.Name(), archivedChannelPrefix(ctf)) {
+			archived = append(archived, channel)
+		}
+	}
+	return archived
+}
+
+// ctfFromArchivedChannel returns the archived CTF the channel belonged to.
+func (s *Server) ctfFromArchivedChannel(ctx context.Context, channelID snowflake.ID) (*ctfbot.CTF, error) {
+	status := ctfbot.CTFStatusArchived
+	ctfs, _, err := s.CTFService.FindCTFs(ctx, ctfbot.CTFFilter{Status: &status})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ctf := range ctfs {
+		if slices.ContainsFunc(s.archivedChannels(ctf), func(channel discord.GuildChannel) bool {
+			return channel.ID() == channelID
+		}) {
+			return ctf, nil
+		}
+	}
+	return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "CTF not found.")
+}
+
+// archivedChannelPrefix returns the prefix of the archived channels of ctf,
+// as Discord would format it.
+func archivedChannelPrefix(ctf *ctfbot.CTF) string {
+	return strings.ToLower(strings.Join(strings.Fields(ctf.Name), "-")) + archiveSeparator
+}
@@ -0,0 +1,282 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/havce/ctfbot"
+)
+
+const (
+	// How long availability slots last, unless told otherwise.
+	DefaultSlotLength = 6 * time.Hour
+
+	// Four rows of five buttons, the last row is for the shortcuts.
+	maxAvailabilitySlots = 20
+
+	// Longest bar shown for a slot in the summary.
+	maxAvailabilityBar = 10
+)
+
+func (s *Server) handleAvailability(event *handler.CommandEvent) error {
+	data := event.SlashCommandInteractionData()
+
+	poll := &ctfbot.AvailabilityPoll{
+		ChannelID: event.Channel().ID().String(),
+	}
+
+	var start, finish time.Time
+	if v, ok := data.OptString("event"); ok {
+		ctftimeEvent := s.findCTFTimeEvent(v)
+		if ctftimeEvent == nil {
+			return Error(event, ctfbot.Errorf(ctfbot.EINVALID, "`%s` is not a CTFTime event.", v))
+		}
+		poll.EventID, poll.Title = ctftimeEvent.ID, ctftimeEvent.Title
+		start, finish = ctftimeEvent.Start, ctftimeEvent.Finish
+	} else {
+		var ctf *ctfbot.CTF
+		var err error
+		if v, ok := data.OptString("ctf"); ok {
+			ctf, err = s.CTFService.FindCTFByName(context.TODO(), v)
+		} else if ctf, err = s.ctfFromChannel(event.Channel().ID()); ctfbot.ErrorCode(err) == ctfbot.ENOTFOUND {
+			err = ctfbot.Errorf(ctfbot.EINVALID, "Pick a CTF or a CTFTime event, or run this command inside a CTF.")
+		}
+		if err != nil {
+			return Error(event, err)
+		}
+
+		poll.EventID, poll.Title = ctf.CTFTimeID, ctf.Name
+		start, finish = ctf.Start, ctf.Finish
+
+		// CTFTime knows better if the dates changed since the CTF was created.
+		if ctf.CTFTimeID != 0 {
+			if ctftimeEvent, err := s.CTFTimeClient.FindEventByID(context.TODO(), ctf.CTFTimeID); err != nil {
+				s.client.Logger().Warn("Couldn't fetch ctftime information", "event_id", ctf.CTFTimeID, "err", err)
+			} else {
+				start, finish = ctftimeEvent.Start, ctftimeEvent.Finish
+			}
+		}
+	}
+
+	if poll.Title == "" {
+		poll.Title = fmt.Sprintf("Event #%d", poll.EventID)
+	}
+
+	if start.IsZero() || !finish.After(start) {
+		return Error(event, ctfbot.Errorf(ctfbot.EINVALID, "The start and end of `%s` aren't known.", poll.Title))
+	}
+
+	slotLength := DefaultSlotLength
+	if v, ok := data.OptInt("slot"); ok && v > 0 {
+		slotLength = time.Duration(v) * time.Hour
+	}
+
+	// Make the slots longer rather than running out of buttons.
+	duration := finish.Sub(start)
+	if slots := (duration + slotLength - 1) / slotLength; slots > maxAvailabilitySlots {
+		slotLength = (duration/maxAvailabilitySlots + time.Hour - 1).Truncate(time.Hour)
+	}
+	poll.Start, poll.SlotLength = start, slotLength
+	poll.Slots = int((duration + slotLength - 1) / slotLength)
+
+	msg, err := s.client.Rest().CreateMessage(event.Channel().ID(), discord.NewMessageCreateBuilder().
+		SetEmbeds(availabilitySummary(poll, nil)).
+		SetContainerComponents(availabilityComponents(poll)...).
+		Build(),
+	)
+	if err != nil {
+		return Error(event, err)
+	}
+
+	// Remember the poll, to take responses.
+	poll.MessageID = msg.ID.String()
+	if err := s.AvailabilityService.CreateAvailabilityPoll(context.TODO(), poll); err != nil {
+		_ = s.client.Rest().DeleteMessage(msg.ChannelID, msg.ID)
+		return Error(event, err)
+	}
+
+	Respond(event, ":calendar: Availability poll created", "Members can now tell when they're available.")
+	return nil
+}
+
+// handleAvailabilitySlot toggles the availability of the user in the slot of
+// the button.
+func (s *Server) handleAvailabilitySlot(event *handler.ComponentEvent) error {
+	slot, err := strconv.Atoi(event.Vars["slot"])
+	if err != nil {
+		return Error(event, ctfbot.Errorf(ctfbot.EINVALID, "Invalid slot."))
+	}
+
+	poll, err := s.AvailabilityService.FindAvailabilityPollByMessageID(context.TODO(), event.Message.ID.String())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	userID := event.User().ID.String()
+	responses, _, err := s.AvailabilityService.FindAvailabilityResponses(context.TODO(), ctfbot.AvailabilityResponseFilter{
+		PollID: &poll.ID,
+		UserID: &userID,
+	})
+	if err != nil {
+		return Error(event, err)
+	}
+
+	slots, toggled := []int{}, false
+	for _, response := range responses {
+		if response.Slot == slot {
+			toggled = true
+			continue
+		}
+		slots = append(slots, response.Slot)
+	}
+	if !toggled {
+		slots = append(slots, slot)
+	}
+	return s.setAvailability(event, poll, slots)
+}
+
+// handleAvailabilityAll marks the user available for the whole event.
+func (s *Server) handleAvailabilityAll(event *handler.ComponentEvent) error {
+	poll, err := s.AvailabilityService.FindAvailabilityPollByMessageID(context.TODO(), event.Message.ID.String())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	slots := make([]int, poll.Slots)
+	for i := range slots {
+		slots[i] = i
+	}
+	return s.setAvailability(event, poll, slots)
+}
+
+// handleAvailabilityNone marks the user unavailable for the whole event.
+func (s *Server) handleAvailabilityNone(event *handler.ComponentEvent) error {
+	poll, err := s.AvailabilityService.FindAvailabilityPollByMessageID(context.TODO(), event.Message.ID.String())
+	if err != nil {
+		return Error(event, err)
+	}
+	return s.setAvailability(event, poll, nil)
+}
+
+// setAvailability replaces the slots the user is available in, refreshes the
+// summary of the poll, and tells the user when they're available.
+func (s *Server) setAvailability(event *handler.ComponentEvent, poll *ctfbot.AvailabilityPoll, slots []int) error {
+	err := s.AvailabilityService.SetAvailability(context.TODO(), poll.ID, event.User().ID.String(), slots)
+	if err != nil {
+		return Error(event, err)
+	}
+
+	responses, _, err := s.AvailabilityService.FindAvailabilityResponses(context.TODO(), ctfbot.AvailabilityResponseFilter{
+		PollID: &poll.ID,
+	})
+	if err != nil {
+		return Error(event, err)
+	}
+
+	_, err = s.client.Rest().UpdateMessage(event.Message.ChannelID, event.Message.ID, discord.NewMessageUpdateBuilder().
+		SetEmbeds(availabilitySummary(poll, responses)).
+		Build())
+	if err != nil {
+		s.client.Logger().Warn("Couldn't update availability summary", "poll_id", poll.ID, "err", err)
+	}
+
+	if len(slots) == 0 {
+		Respond(event, ":calendar: Availability updated", "You're not available at all.")
+		return nil
+	}
+
+	slices.Sort(slots)
+	lines := make([]string, 0, len(slots))
+	for _, slot := range slots {
+		start, end := poll.SlotStart(slot), poll.SlotStart(slot+1)
+		lines = append(lines, fmt.Sprintf("- %s to %s", formatTime(&start), formatTime(&end)))
+	}
+	Respond(event, ":calendar: Availability updated", "You're available:\n"+strings.Join(lines, "\n"))
+	return nil
+}
+
+// availabilitySummary returns the embed showing how many members are
+// available in each slot of poll.
+func availabilitySummary(poll *ctfbot.AvailabilityPoll, responses []*ctfbot.AvailabilityResponse) discord.Embed {
+	headcount, members := make([]int, poll.Slots), map[string]bool{}
+	for _, response := range responses {
+		if response.Slot < poll.Slots {
+			headcount[response.Slot]++
+		}
+		members[response.UserID] = true
+	}
+
+	best := slices.Max(headcount)
+
+	lines := make([]string, 0, poll.Slots+2)
+	lines = append(lines, "Press the buttons below to toggle the slots you can play in, they're in UTC.\n")
+	for i, n := range headcount {
+		start := poll.SlotStart(i)
+		lines = append(lines, fmt.Sprintf("`%s` %s %s **%d**", slotLabel(poll, i), formatShortTime(&start), heatBar(n, best), n))
+	}
+
+	if best > 0 {
+		slot := slices.Index(headcount, best)
+		start := poll.SlotStart(slot)
+		lines = append(lines, fmt.Sprintf("\nMost members are available %s.", formatTime(&start)))
+	}
+
+	return discord.NewEmbedBuilder().
+		SetTitle(truncate(fmt.Sprintf(":calendar: Who can play %s?", poll.Title), 256)).
+		SetColor(ColorNotQuiteBlack).
+		SetDescription(truncate(strings.Join(lines, "\n"), maxEmbedDescriptionLength)).
+		SetFooterTextf("%d members responded", len(members)).
+		Build()
+}
+
+// availabilityComponents returns the buttons toggling the slots of poll, and
+// the shortcuts for the whole event.
+func availabilityComponents(poll *ctfbot.AvailabilityPoll) []discord.ContainerComponent {
+	rows := []discord.ContainerComponent{}
+
+	buttons := []discord.InteractiveComponent{}
+	for i := 0; i < poll.Slots; i++ {
+		buttons = append(buttons, discord.NewSecondaryButton(slotLabel(poll, i), fmt.Sprintf("/availability/slot/%d", i)))
+		if len(buttons) == 5 || i == poll.Slots-1 {
+			rows = append(rows, discord.NewActionRow(buttons...))
+			buttons = []discord.InteractiveComponent{}
+		}
+	}
+
+	return append(rows, discord.NewActionRow(
+		discord.NewSuccessButton("Whole event", "/availability/all"),
+		discord.NewDangerButton("Not available", "/availability/none"),
+	))
+}
+
+// slotLabel returns the label of the i-th slot of poll.
+func slotLabel(poll *ctfbot.AvailabilityPoll, i int) string {
+	return poll.SlotStart(i).UTC().Format("Mon 15:04")
+}
+
+// heatBar returns a bar as long as n, colored after how close n is to best.
+func heatBar(n, best int) string {
+	if n == 0 {
+		return "⬛"
+	}
+
+	square := "🟧"
+	switch ratio := float64(n) / float64(best); {
+	case ratio >= 0.75:
+		square = "🟩"
+	case ratio >= 0.5:
+		square = "🟨"
+	}
+
+	bar := strings.Repeat(square, min(n, maxAvailabilityBar))
+	if n > maxAvailabilityBar {
+		bar += "…"
+	}
+	return bar
+}
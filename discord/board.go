@@ -0,0 +1,140 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/havce/ctfbot"
+)
+
+// Embed descriptions can't be longer than 4096 characters.
+const maxBoardLength = 4096
+
+// refreshBoard is like updateBoard, but it only logs failures. A stale board
+// is no reason to fail the command that triggered the refresh.
+func (s *Server) refreshBoard(ctx context.Context, ctf *ctfbot.CTF) {
+	if err := s.updateBoard(ctx, ctf); err != nil {
+		s.client.Logger().Warn("Couldn't update challenge board", "ctf", ctf.Name, "err", err)
+	}
+}
+
+// updateBoard edits in place the pinned message listing the challenges of
+// ctf. The message is created and pinned in the general channel the first
+// time around, or if somebody deleted it.
+func (s *Server) updateBoard(ctx context.Context, ctf *ctfbot.CTF) error {
+	general, err := s.generalChannel(ctf)
+	if err != nil {
+		return err
+	}
+
+	embed, err := s.boardEmbed(ctx, ctf)
+	if err != nil {
+		return err
+	}
+
+	// Try to edit the existing board first. Only post a new one if it was
+	// deleted, other errors are likely to go away on the next refresh.
+	if messageID, err := snowflake.Parse(ctf.BoardMessageID); err == nil {
+		_, err = s.client.Rest().UpdateMessage(general.ID(), messageID,
+			discord.NewMessageUpdateBuilder().SetEmbeds(embed).Build())
+		if !isNotFound(err) {
+			return err
+		}
+	}
+
+	msg, err := s.client.Rest().CreateMessage(general.ID(),
+		discord.NewMessageCreateBuilder().SetEmbeds(embed).Build())
+	if err != nil {
+		return err
+	}
+
+	if err := s.client.Rest().PinMessage(general.ID(), msg.ID); err != nil {
+		return err
+	}
+
+	boardMessageID := msg.ID.String()
+	_, err = s.CTFService.UpdateCTF(ctx, ctf.Name, ctfbot.CTFUpdate{
+		BoardMessageID: &boardMessageID,
+	})
+	return err
+}
+
+// boardEmbed renders the status of every challenge of the CTF.
+func (s *Server) boardEmbed(ctx context.Context, ctf *ctfbot.CTF) (discord.Embed, error) {
+	chals, _, err := s.ChallengeService.FindChallenges(ctx, ctfbot.ChallengeFilter{CTFID: &ctf.ID})
+	if err != nil {
+		return discord.Embed{}, err
+	}
+
+	solves, _, err := s.SolveService.FindSolves(ctx, ctfbot.SolveFilter{CTFID: &ctf.ID})
+	if err != nil {
+		return discord.Embed{}, err
+	}
+
+	solvers := make(map[int]string, len(solves))
+	for _, solve := range solves {
+		solvers[solve.ChallengeID] = solve.UserID
+	}
+
+	claims, _, err := s.ClaimService.FindClaims(ctx, ctfbot.ClaimFilter{CTFID: &ctf.ID})
+	if err != nil {
+		return discord.Embed{}, err
+	}
+
+	workers := make(map[int][]string)
+	for _, claim := range claims {
+		workers[claim.ChallengeID] = append(workers[claim.ChallengeID], fmt.Sprintf("<@%s>", claim.UserID))
+	}
+
+	solved := 0
+	lines := make([]string, 0, len(chals))
+	for _, chal := range chals {
+		lines = append(lines, boardLine(chal, solvers[chal.ID], workers[chal.ID]))
+		if chal.Solved {
+			solved++
+		}
+	}
+
+	description := strings.Join(lines, "\n")
+	if description == "" {
+		description = "No challenges yet. Use `/chal` to add one."
+	}
+
+	return discord.NewEmbedBuilder().
+		SetTitle(fmt.Sprintf(":clipboard: %s challenges", ctf.Name)).
+		SetColor(ColorBlurple).
+		SetDescription(truncate(description, maxBoardLength)).
+		SetFooterTextf("%d/%d solved", solved, len(chals)).
+		SetTimestamp(time.Now()).
+		Build(), nil
+}
+
+// boardLine renders a single challenge of the board.
+func boardLine(chal *ctfbot.Challenge, solverID string, workers []string) string {
+	status := ":white_large_square:"
+	if chal.Blood {
+		status = bloodEmoji
+	} else if chal.Solved {
+		status = flagEmoji
+	}
+
+	line := fmt.Sprintf("%s **%s**", status, chal.Name)
+	if chal.Category != "" {
+		line += " · " + chal.Category
+	}
+	if chal.Points > 0 {
+		line += fmt.Sprintf(" · %d pts", chal.Points)
+	}
+
+	if chal.Solved && solverID != "" {
+		line += fmt.Sprintf(" · solved by <@%s>", solverID)
+	} else if !chal.Solved && len(workers) > 0 {
+		line += " · :technologist: " + strings.Join(workers, ", ")
+	}
+
+	return line
+}
@@ -0,0 +1,398 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/havce/ctfbot"
+)
+
+const (
+	flagEmoji  = "🚩"
+	bloodEmoji = "🩸"
+)
+
+// handleFlag marks the challenge as solved. Blood status is detected
+// automatically, unless forceBlood is set by an admin.
+func (s *Server) handleFlag(forceBlood bool) func(event *handler.CommandEvent) error {
+	return func(event *handler.CommandEvent) error {
+		if !s.flagAllowed(event.Channel().Name()) {
+			return Error(event, ctfbot.Errorf(
+				ctfbot.EINVALID, "You cannot flag here."))
+		}
+
+		// Check if someone has already flagged this.
+		if utf8.RuneCountInString(event.Channel().Name()) > 0 {
+			// Decode first rune. We don't care about the byte length.
+			c, _ := utf8.DecodeRuneInString(event.Channel().Name())
+
+			blocklist := []string{flagEmoji, bloodEmoji}
+			// Check against blocklist.
+			if slices.Contains(blocklist, string(c)) {
+				return Error(event, ctfbot.Errorf(ctfbot.EINVALID, "Somebody has already flagged this."))
+			}
+		}
+
+		ctf, err := s.ctfFromChannel(event.Channel().ID())
+		if err != nil {
+			return Error(event, err)
+		}
+
+		chal, err := s.challengeFromChannel(context.TODO(), ctf, event.Channel().ID(), event.Channel().Name())
+		if err != nil {
+			return Error(event, err)
+		}
+
+		blood := forceBlood
+		if !blood {
+			if blood, err = s.isFirstBlood(context.TODO(), ctf, chal); err != nil {
+				return Error(event, err)
+			}
+		}
+
+		// Persist the solve, along with the flag if it was provided.
+		solve := &ctfbot.Solve{
+			ChallengeID: chal.ID,
+			UserID:      event.User().ID.String(),
+			Blood:       blood,
+			Flag:        event.SlashCommandInteractionData().String("flag"),
+		}
+		if err := s.flagChallenge(context.TODO(), ctf, solve, event.Channel().ID(), event.Channel().Name()); err != nil {
+			return Error(event, err)
+		}
+
+		// Delete response.
+		if err := event.DeleteInteractionResponse(); err != nil {
+			return err
+		}
+
+		// Show everyone who flagged this! Publicly post this.
+		return s.announceFlag(event.Channel().ID(), event.Channel().Name(), event.User().String(), blood)
+	}
+}
+
+// flagChallenge stores solve, and marks the channel of the challenge as
+// solved by prepending the flag or blood emoji to its name.
+func (s *Server) flagChallenge(ctx context.Context, ctf *ctfbot.CTF, solve *ctfbot.Solve, channelID snowflake.ID, channelName string) error {
+	if err := s.SolveService.CreateSolve(ctx, solve); err != nil {
+		return err
+	}
+
+	// Prepend the prefix emoji.
+	newName := solvedPrefix(solve.Blood) + " " + channelName
+
+	// Update channel name with the prefixed emoji of flag or blood. The
+	// topic goes along, as the channel can only be edited twice every ten
+	// minutes.
+	topic := "Flagged, nobody needs to work on it anymore."
+	_, err := s.client.Rest().UpdateChannel(channelID, discord.GuildTextChannelUpdate{
+		Name:  &newName,
+		Topic: &topic,
+	})
+	if err != nil {
+		// Without the emoji the challenge doesn't look solved, so forget the
+		// solve and let it be flagged again.
+		if err := s.SolveService.DeleteSolve(ctx, solve.ID); err != nil {
+			s.client.Logger().Warn("Couldn't delete solve", "challenge_id", solve.ChallengeID, "err", err)
+		}
+		return err
+	}
+
+	// Nobody is working on it anymore.
+	s.dequeueWorkersTopic(channelID)
+	if err := s.clearClaims(ctx, solve.ChallengeID); err != nil {
+		s.client.Logger().Warn("Couldn't clear claims", "challenge_id", solve.ChallengeID, "err", err)
+	}
+
+	s.refreshBoard(ctx, ctf)
+	return nil
+}
+
+// announceFlag publicly cheers for solver in the channel of the challenge.
+func (s *Server) announceFlag(channelID snowflake.ID, channelName, solver string, blood bool) error {
+	_, err := s.client.Rest().CreateMessage(channelID,
+		discord.NewMessageCreateBuilder().
+			SetEphemeral(false).
+			SetEmbeds(messageEmbedSuccess(solvedPrefix(blood)+" New flag!",
+				fmt.Sprintf("%s! %s has flagged `%s`.",
+					cheer(), solver, channelName))).
+			Build())
+	return err
+}
+
+// solvedPrefix returns the emoji marking a challenge as solved.
+func solvedPrefix(blood bool) string {
+	if blood {
+		return bloodEmoji
+	}
+	return flagEmoji
+}
+
+func (s *Server) handleUnflag(event *handler.CommandEvent) error {
+	if !s.flagAllowed(event.Channel().Name()) {
+		return Error(event, ctfbot.Errorf(
+			ctfbot.EINVALID, "You cannot unflag here."))
+	}
+
+	newName := stripSolvedPrefix(event.Channel().Name())
+	if newName == event.Channel().Name() {
+		return Error(event, ctfbot.Errorf(ctfbot.EINVALID, "Nobody has flagged this yet."))
+	}
+
+	ctf, err := s.ctfFromChannel(event.Channel().ID())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	chal, err := s.challengeFromChannel(context.TODO(), ctf, event.Channel().ID(), event.Channel().Name())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	// Remove any stored solve.
+	solves, _, err := s.SolveService.FindSolves(context.TODO(), ctfbot.SolveFilter{ChallengeID: &chal.ID})
+	if err != nil {
+		return Error(event, err)
+	}
+	for _, solve := range solves {
+		if err := s.SolveService.DeleteSolve(context.TODO(), solve.ID); err != nil {
+			return Error(event, err)
+		}
+	}
+
+	// Strip the flag or blood emoji from the channel name.
+	topic := workersTopic(nil)
+	_, err = s.client.Rest().UpdateChannel(event.Channel().ID(), discord.GuildTextChannelUpdate{
+		Name:  &newName,
+		Topic: &topic,
+	})
+	if err != nil {
+		return Error(event, err)
+	}
+
+	s.refreshBoard(context.TODO(), ctf)
+
+	// Delete response.
+	if err := event.DeleteInteractionResponse(); err != nil {
+		return err
+	}
+
+	// Let everyone know the solve was reverted.
+	_, err = s.client.Rest().CreateMessage(event.Channel().ID(),
+		discord.NewMessageCreateBuilder().
+			SetEphemeral(false).
+			SetEmbeds(discord.NewEmbedBuilder().
+				SetTitle(":leftwards_arrow_with_hook: Flag reverted").
+				SetColor(ColorYellow).
+				SetDescriptionf("%s has marked `%s` as unsolved.", event.User().String(), newName).
+				Build()).
+			Build())
+	return err
+}
+
+func (s *Server) handleNewChal(event *handler.CommandEvent) error {
+	data := event.SlashCommandInteractionData()
+
+	// Get parent ID of the current channel. The middleware already checked
+	// it belongs to a CTF, but it might have been deleted in the meantime.
+	parentChannel, err := s.parentChannel(event.Channel().ID())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	ctf, err := s.ctfFromCategory(context.TODO(), parentChannel)
+	if err != nil {
+		return Error(event, err)
+	}
+
+	chal := &ctfbot.Challenge{
+		Name:     data.String("name"),
+		Category: data.String("category"),
+		Points:   data.Int("points"),
+	}
+	if err := s.createChallenge(context.TODO(), *event.GuildID(), ctf, parentChannel.ID(), chal, event.User()); err != nil {
+		return Error(event, err)
+	}
+
+	s.refreshBoard(context.TODO(), ctf)
+
+	Respond(event, "New channel created", fmt.Sprintf("Successfully added channel `%s`.", challengeChannelName(chal)))
+	return nil
+}
+
+// createChallenge creates a text channel for chal inside the CTF category
+// parentID, and stores the challenge. It fails with ECONFLICT if the
+// challenge already exists, or already has a channel.
+func (s *Server) createChallenge(ctx context.Context, guildID snowflake.ID, ctf *ctfbot.CTF, parentID snowflake.ID, chal *ctfbot.Challenge, author discord.User) error {
+	channelName := challengeChannelName(chal)
+
+	// Channel names are normalized by Discord, so look for the challenge
+	// itself before comparing them.
+	if _, n, err := s.ChallengeService.FindChallenges(ctx, ctfbot.ChallengeFilter{
+		CTFID: &ctf.ID,
+		Name:  &chal.Name,
+		Limit: 1,
+	}); err != nil {
+		return err
+	} else if n > 0 {
+		return ctfbot.Errorf(ctfbot.ECONFLICT, "Somebody has already created `%s`.", chal.Name)
+	}
+
+	// Check if there's another sibling channel with the same name.
+	for _, channel := range s.childChannels(parentID) {
+		// Replace blood and flag indicators. We don't want to add an
+		// already solved challenge.
+		if channelName == stripSolvedPrefix(channel.Name()) {
+			return ctfbot.Errorf(ctfbot.ECONFLICT, "Somebody has already created `%s`.", chal.Name)
+		}
+	}
+
+	// Search @everyone role ID.
+	var everyoneID *snowflake.ID
+	s.client.Caches().RolesForEach(guildID, func(role discord.Role) {
+		if role.Name == "@everyone" {
+			everyoneID = &role.ID
+		}
+	})
+
+	roleID, err := snowflake.Parse(ctf.RoleID)
+	if err != nil {
+		return err
+	}
+
+	role, found := s.client.Caches().Role(guildID, roleID)
+	if !found {
+		return ctfbot.Errorf(ctfbot.EINTERNAL, "Couldn't find player role for `%s`. Maybe it was deleted?", ctf.Name)
+	}
+
+	// Create the channel with our custom permissions.
+	// No one but the current role members should see the channel.
+	channel, err := s.client.Rest().CreateGuildChannel(guildID, discord.GuildTextChannelCreate{
+		Name:     channelName,
+		ParentID: parentID,
+		PermissionOverwrites: []discord.PermissionOverwrite{
+			discord.RolePermissionOverwrite{
+				RoleID: *everyoneID,
+				Deny:   discord.PermissionsAll,
+			},
+			discord.RolePermissionOverwrite{
+				RoleID: role.ID,
+				Allow:  DefaultChannelPrivileges,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Keep track of the challenge.
+	chal.CTFID = ctf.ID
+	chal.ChannelID = channel.ID().String()
+	if err := s.ChallengeService.CreateChallenge(ctx, chal); err != nil {
+		// Don't leave behind a channel we know nothing about.
+		_ = s.client.Rest().DeleteChannel(channel.ID())
+		return err
+	}
+
+	_, err = s.client.Rest().CreateMessage(channel.ID(), discord.NewMessageCreateBuilder().
+		SetEmbeds(messageEmbedChallenge(chal, author)).Build())
+	return err
+}
+
+// challengeChannelName returns the name of the channel of chal. The name is
+// prefixed with the category, so that challenges of the same kind are easy
+// to spot.
+func challengeChannelName(chal *ctfbot.Challenge) string {
+	if chal.Category != "" {
+		return chal.Category + "-" + chal.Name
+	}
+	return chal.Name
+}
+
+// messageEmbedChallenge builds the embed introducing a new challenge.
+func messageEmbedChallenge(chal *ctfbot.Challenge, author discord.User) discord.Embed {
+	category := chal.Category
+	if category == "" {
+		category = "Unknown"
+	}
+
+	points := "N/A"
+	if chal.Points > 0 {
+		points = strconv.Itoa(chal.Points)
+	}
+
+	return discord.NewEmbedBuilder().
+		SetTitle("New challenge!").
+		SetColor(ColorGreen).
+		SetDescriptionf("%s has created `%s`", author.String(), chal.Name).
+		AddField("Category", category, true).
+		AddField("Points", points, true).
+		Build()
+}
+
+// isFirstBlood reports whether solving chal right now is a first blood. If
+// the CTF is linked to a platform, it is a blood when nobody else has solved
+// the challenge. Otherwise, it is the first flag our team captures in the CTF.
+// When the platform can't tell, it's a plain flag: admins can still /blood.
+func (s *Server) isFirstBlood(ctx context.Context, ctf *ctfbot.CTF, chal *ctfbot.Challenge) (bool, error) {
+	platform, err := s.platformForCTF(ctx, ctf)
+	if ctfbot.ErrorCode(err) == ctfbot.ENOTFOUND {
+		_, n, err := s.SolveService.FindSolves(ctx, ctfbot.SolveFilter{CTFID: &ctf.ID, Limit: 1})
+		if err != nil {
+			return false, err
+		}
+		return n == 0, nil
+	} else if err != nil {
+		s.client.Logger().Warn("Couldn't connect to platform", "ctf", ctf.Name, "err", err)
+		return false, nil
+	}
+
+	challenges, err := platform.FindChallenges(ctx)
+	if err != nil {
+		s.client.Logger().Warn("Couldn't fetch challenges from platform", "ctf", ctf.Name, "err", err)
+		return false, nil
+	}
+
+	for _, c := range challenges {
+		// Our own solve may have already been counted.
+		if strings.EqualFold(c.Name, chal.Name) {
+			return c.Solves <= 1, nil
+		}
+	}
+	return false, nil
+}
+
+// challengeFromChannel returns the challenge associated with channelID.
+// Channels created before challenges were stored in the database are
+// registered on the fly.
+func (s *Server) challengeFromChannel(ctx context.Context, ctf *ctfbot.CTF, channelID snowflake.ID, channelName string) (*ctfbot.Challenge, error) {
+	chal, err := s.ChallengeService.FindChallengeByChannelID(ctx, channelID.String())
+	if ctfbot.ErrorCode(err) != ctfbot.ENOTFOUND {
+		return chal, err
+	}
+
+	chal = &ctfbot.Challenge{
+		CTFID:     ctf.ID,
+		Name:      stripSolvedPrefix(channelName),
+		ChannelID: channelID.String(),
+	}
+	if err := s.ChallengeService.CreateChallenge(ctx, chal); err != nil {
+		return nil, err
+	}
+	return chal, nil
+}
+
+// stripSolvedPrefix removes the flag and blood indicators from a channel name.
+func stripSolvedPrefix(name string) string {
+	// Append "-" to emojis, because Discord replaces spaces with dashes.
+	return strings.NewReplacer(
+		flagEmoji+"-", "",
+		bloodEmoji+"-", "").Replace(name)
+}
@@ -0,0 +1,224 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/havce/ctfbot"
+)
+
+const (
+	// Channel topics can't be longer than 1024 characters.
+	maxTopicLength = 1024
+
+	// How often the topics listing the workers are updated. Discord allows
+	// two edits of a channel every ten minutes, shared with renames, so the
+	// claims made in the meantime are batched into a single edit.
+	topicInterval = 5 * time.Minute
+)
+
+func (s *Server) handleWorking(event *handler.CommandEvent) error {
+	if !s.flagAllowed(event.Channel().Name()) {
+		return Error(event, ctfbot.Errorf(
+			ctfbot.EINVALID, "You can only work on a challenge from its channel."))
+	}
+
+	ctf, err := s.ctfFromChannel(event.Channel().ID())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	chal, err := s.challengeFromChannel(context.TODO(), ctf, event.Channel().ID(), event.Channel().Name())
+	if err != nil {
+		return Error(event, err)
+	} else if chal.Solved {
+		return Error(event, ctfbot.Errorf(ctfbot.EINVALID, "`%s` has already been flagged.", chal.Name))
+	}
+
+	err = s.ClaimService.CreateClaim(context.TODO(), &ctfbot.Claim{
+		ChallengeID: chal.ID,
+		UserID:      event.User().ID.String(),
+	})
+	if err != nil {
+		return Error(event, err)
+	}
+
+	s.queueWorkersTopic(chal, event.Channel().ID())
+
+	s.refreshBoard(context.TODO(), ctf)
+
+	Respond(event, "Good luck!", fmt.Sprintf("You're now working on `%s`.", chal.Name))
+	return nil
+}
+
+func (s *Server) handleStop(event *handler.CommandEvent) error {
+	ctf, err := s.ctfFromChannel(event.Channel().ID())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	chal, err := s.ChallengeService.FindChallengeByChannelID(context.TODO(), event.Channel().ID().String())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	userID := event.User().ID.String()
+	claims, _, err := s.ClaimService.FindClaims(context.TODO(), ctfbot.ClaimFilter{
+		ChallengeID: &chal.ID,
+		UserID:      &userID,
+	})
+	if err != nil {
+		return Error(event, err)
+	} else if len(claims) == 0 {
+		return Error(event, ctfbot.Errorf(ctfbot.ENOTFOUND, "You're not working on `%s`.", chal.Name))
+	}
+
+	for _, claim := range claims {
+		if err := s.ClaimService.DeleteClaim(context.TODO(), claim.ID); err != nil {
+			return Error(event, err)
+		}
+	}
+
+	s.queueWorkersTopic(chal, event.Channel().ID())
+
+	s.refreshBoard(context.TODO(), ctf)
+
+	Respond(event, "Take a break", fmt.Sprintf("You're no longer working on `%s`.", chal.Name))
+	return nil
+}
+
+func (s *Server) handleWho(event *handler.CommandEvent) error {
+	ctf, err := s.ctfFromChannel(event.Channel().ID())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	claims, _, err := s.ClaimService.FindClaims(context.TODO(), ctfbot.ClaimFilter{CTFID: &ctf.ID})
+	if err != nil {
+		return Error(event, err)
+	}
+
+	if len(claims) == 0 {
+		Respond(event, "Who's working on what", "Nobody is working on anything right now.")
+		return nil
+	}
+
+	chals, _, err := s.ChallengeService.FindChallenges(context.TODO(), ctfbot.ChallengeFilter{CTFID: &ctf.ID})
+	if err != nil {
+		return Error(event, err)
+	}
+
+	names := make(map[int]string, len(chals))
+	for _, chal := range chals {
+		names[chal.ID] = chal.Name
+	}
+
+	lines := make([]string, 0, len(claims))
+	for _, claim := range claims {
+		lines = append(lines, fmt.Sprintf("`%s`: <@%s> since %s",
+			names[claim.ChallengeID], claim.UserID, formatRelativeTime(&claim.StartedAt)))
+	}
+
+	Respond(event, "Who's working on what", truncate(strings.Join(lines, "\n"), maxBoardLength))
+	return nil
+}
+
+// queueWorkersTopic marks the topic of the channel of chal to be updated on
+// the next run of updateTopics.
+func (s *Server) queueWorkersTopic(chal *ctfbot.Challenge, channelID snowflake.ID) {
+	s.topicMu.Lock()
+	defer s.topicMu.Unlock()
+	s.topics[channelID] = chal.ID
+}
+
+// dequeueWorkersTopic forgets a queued update of the topic of channelID.
+func (s *Server) dequeueWorkersTopic(channelID snowflake.ID) {
+	s.topicMu.Lock()
+	defer s.topicMu.Unlock()
+	delete(s.topics, channelID)
+}
+
+// updateTopics updates the topics queued since the last run.
+func (s *Server) updateTopics(ctx context.Context) {
+	s.topicMu.Lock()
+	topics := s.topics
+	s.topics = make(map[snowflake.ID]int)
+	s.topicMu.Unlock()
+
+	for channelID, chalID := range topics {
+		chal, err := s.ChallengeService.FindChallengeByID(ctx, chalID)
+		if err != nil {
+			s.client.Logger().Warn("Couldn't find challenge", "challenge_id", chalID, "err", err)
+			continue
+		}
+
+		// The topic was already updated along with the name.
+		if chal.Solved {
+			continue
+		}
+
+		if err := s.updateWorkersTopic(ctx, chal, channelID); err != nil {
+			s.client.Logger().Warn("Couldn't update workers topic", "challenge", chal.Name, "err", err)
+		}
+	}
+}
+
+// updateWorkersTopic lists the users working on chal in the topic of its
+// channel.
+func (s *Server) updateWorkersTopic(ctx context.Context, chal *ctfbot.Challenge, channelID snowflake.ID) error {
+	workers, err := s.workers(ctx, chal)
+	if err != nil {
+		return err
+	}
+
+	topic := workersTopic(workers)
+	_, err = s.client.Rest().UpdateChannel(channelID, discord.GuildTextChannelUpdate{
+		Topic: &topic,
+	})
+	return err
+}
+
+// workersTopic returns the topic of a channel listing workers.
+func workersTopic(workers []string) string {
+	// An empty topic would be omitted from the request, and not cleared.
+	topic := "Nobody is working on it."
+	if len(workers) > 0 {
+		topic = "Working on it: " + strings.Join(workers, ", ")
+	}
+	return truncate(topic, maxTopicLength)
+}
+
+// clearClaims deletes the claims on a challenge, now that nobody needs to
+// work on it.
+func (s *Server) clearClaims(ctx context.Context, challengeID int) error {
+	claims, _, err := s.ClaimService.FindClaims(ctx, ctfbot.ClaimFilter{ChallengeID: &challengeID})
+	if err != nil {
+		return err
+	}
+
+	for _, claim := range claims {
+		if err := s.ClaimService.DeleteClaim(ctx, claim.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workers returns the mentions of the users working on chal.
+func (s *Server) workers(ctx context.Context, chal *ctfbot.Challenge) ([]string, error) {
+	claims, _, err := s.ClaimService.FindClaims(ctx, ctfbot.ClaimFilter{ChallengeID: &chal.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	workers := make([]string, 0, len(claims))
+	for _, claim := range claims {
+		workers = append(workers, fmt.Sprintf("<@%s>", claim.UserID))
+	}
+	return workers, nil
+}
@@ -1,6 +1,9 @@
 package discord
 
-import "github.com/disgoorg/disgo/discord"
+import (
+	"github.com/disgoorg/disgo/discord"
+	"github.com/havce/ctfbot"
+)
 
 var commands = []discord.ApplicationCommandCreate{
 	discord.SlashCommandCreate{
@@ -30,6 +33,33 @@ var commands = []discord.ApplicationCommandCreate{
 				Name:        "weeks",
 				Description: "How many weeks away to search available CTFs.",
 			},
+			discord.ApplicationCommandOptionString{
+				Name:        "format",
+				Description: "Only CTFs of this format.",
+				Choices: []discord.ApplicationCommandOptionChoiceString{
+					{Name: "Jeopardy", Value: "Jeopardy"},
+					{Name: "Attack-Defense", Value: "Attack-Defense"},
+					{Name: "Mixed", Value: "Mixed"},
+				},
+			},
+			discord.ApplicationCommandOptionBool{
+				Name:        "onsite",
+				Description: "Only onsite CTFs if true, only online ones if false.",
+			},
+			discord.ApplicationCommandOptionFloat{
+				Name:        "weight",
+				Description: "Only CTFs weighing at least this much on CTFTime.",
+			},
+			discord.ApplicationCommandOptionString{
+				Name:        "restrictions",
+				Description: "Only CTFs open to these teams.",
+				Choices: []discord.ApplicationCommandOptionChoiceString{
+					{Name: "Open", Value: "Open"},
+					{Name: "Academic", Value: "Academic"},
+					{Name: "Prequalified", Value: "Prequalified"},
+					{Name: "High-school", Value: "High-school"},
+				},
+			},
 		},
 	},
 	discord.SlashCommandCreate{
@@ -40,15 +70,70 @@ var commands = []discord.ApplicationCommandCreate{
 				Name:        "weeks",
 				Description: "How many weeks away to search available CTFs.",
 			},
+			discord.ApplicationCommandOptionString{
+				Name:        "duration",
+				Description: "How long the vote lasts, like 24h. Defaults to a day.",
+			},
+			discord.ApplicationCommandOptionBool{
+				Name:        "create",
+				Description: "Whether to create the winning CTF once the vote is over.",
+			},
+			discord.ApplicationCommandOptionString{
+				Name:        "mode",
+				Description: "How votes are cast. Defaults to a single choice.",
+				Choices: []discord.ApplicationCommandOptionChoiceString{
+					{Name: "Single choice", Value: ctfbot.PollSingle},
+					{Name: "Approval", Value: ctfbot.PollApproval},
+					{Name: "Ranked choice", Value: ctfbot.PollRanked},
+				},
+			},
+			discord.ApplicationCommandOptionInt{
+				Name:        "candidates",
+				Description: "How many CTFs are up for a vote, at most 25. Defaults to 15.",
+			},
+		},
+	},
+	discord.SlashCommandCreate{
+		Name:        "availability",
+		Description: "[admin] Ask members when they can play a CTF",
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionString{
+				Name:        "ctf",
+				Description: "CTF name. Defaults to the CTF you're in.",
+			},
+			discord.ApplicationCommandOptionString{
+				Name:        "event",
+				Description: "CTFTime event ID or URL, for CTFs not created yet.",
+			},
+			discord.ApplicationCommandOptionInt{
+				Name:        "slot",
+				Description: "How many hours each time slot lasts. Defaults to 6.",
+			},
 		},
 	},
 	discord.SlashCommandCreate{
 		Name:        "flag",
-		Description: "Marks the challenge as solved with a 🚩 emoji.",
+		Description: "Marks the challenge as solved, with a 🩸 emoji if it was a first blood.",
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionString{
+				Name:        "flag",
+				Description: "The flag, kept for writeups",
+			},
+		},
 	},
 	discord.SlashCommandCreate{
 		Name:        "blood",
-		Description: "Marks the challenge as solved with a 🩸 emoji.",
+		Description: "[admin] Marks the challenge as first blooded with a 🩸 emoji.",
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionString{
+				Name:        "flag",
+				Description: "The flag, kept for writeups",
+			},
+		},
+	},
+	discord.SlashCommandCreate{
+		Name:        "unflag",
+		Description: "Reverts a 🚩 or 🩸 solve on the challenge.",
 	},
 	discord.SlashCommandCreate{
 		Name:        "delete",
@@ -60,9 +145,162 @@ var commands = []discord.ApplicationCommandCreate{
 		Options: []discord.ApplicationCommandOption{
 			discord.ApplicationCommandOptionString{
 				Name:        "name",
-				Description: "Enable voting",
+				Description: "Challenge name",
 				Required:    true,
 			},
+			discord.ApplicationCommandOptionString{
+				Name:        "category",
+				Description: "Challenge category",
+				Choices: []discord.ApplicationCommandOptionChoiceString{
+					{Name: ctfbot.CategoryPwn, Value: ctfbot.CategoryPwn},
+					{Name: ctfbot.CategoryWeb, Value: ctfbot.CategoryWeb},
+					{Name: ctfbot.CategoryCrypto, Value: ctfbot.CategoryCrypto},
+					{Name: ctfbot.CategoryRev, Value: ctfbot.CategoryRev},
+					{Name: ctfbot.CategoryMisc, Value: ctfbot.CategoryMisc},
+				},
+			},
+			discord.ApplicationCommandOptionInt{
+				Name:        "points",
+				Description: "How many points the challenge is worth",
+			},
 		},
 	},
+	discord.SlashCommandCreate{
+		Name:        "working",
+		Description: "Let everyone know you're working on this challenge.",
+	},
+	discord.SlashCommandCreate{
+		Name:        "stop",
+		Description: "Stop working on this challenge.",
+	},
+	discord.SlashCommandCreate{
+		Name:        "who",
+		Description: "List who is working on what in the CTF.",
+	},
+	discord.SlashCommandCreate{
+		Name:        "import",
+		Description: "[admin] Imports the challenges from the CTF platform.",
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionString{
+				Name:        "url",
+				Description: "Platform base URL, defaults to the one in the CTF credentials",
+			},
+			discord.ApplicationCommandOptionString{
+				Name:        "platform",
+				Description: "Platform the CTF runs on, defaults to CTFd",
+				Choices: []discord.ApplicationCommandOptionChoiceString{
+					{Name: "CTFd", Value: ctfbot.PlatformCTFd},
+					{Name: "rCTF", Value: ctfbot.PlatformRCTF},
+				},
+			},
+			discord.ApplicationCommandOptionString{
+				Name:        "token",
+				Description: "CTFd access token or rCTF team token",
+			},
+			discord.ApplicationCommandOptionString{
+				Name:        "session",
+				Description: "CTFd session cookie, if you don't have a token",
+			},
+		},
+	},
+	discord.SlashCommandCreate{
+		Name:        "creds",
+		Description: "Credentials of the CTF platform.",
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "set",
+				Description: "[admin] Sets the credentials of the CTF platform.",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{
+						Name:        "url",
+						Description: "Platform base URL",
+						Required:    true,
+					},
+					discord.ApplicationCommandOptionString{
+						Name:        "platform",
+						Description: "Platform the CTF runs on, if supported",
+						Choices: []discord.ApplicationCommandOptionChoiceString{
+							{Name: "CTFd", Value: ctfbot.PlatformCTFd},
+							{Name: "rCTF", Value: ctfbot.PlatformRCTF},
+						},
+					},
+					discord.ApplicationCommandOptionString{
+						Name:        "team",
+						Description: "Team name",
+					},
+					discord.ApplicationCommandOptionString{
+						Name:        "password",
+						Description: "Team password",
+					},
+					discord.ApplicationCommandOptionString{
+						Name:        "token",
+						Description: "CTFd access token or rCTF team token",
+					},
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "show",
+				Description: "Shows the credentials of the CTF platform.",
+			},
+		},
+	},
+	discord.SlashCommandCreate{
+		Name:        "scoreboard",
+		Description: "Shows the scoreboard of the CTF platform.",
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionInt{
+				Name:        "top",
+				Description: "How many teams to show",
+			},
+		},
+	},
+	discord.SlashCommandCreate{
+		Name:        "schedule",
+		Description: "[admin] Shows or changes when the CTF registrations close and the CTF is archived.",
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionString{
+				Name:        "close",
+				Description: "Close registrations this long after the start, e.g. 2h, or never",
+			},
+			discord.ApplicationCommandOptionInt{
+				Name:        "archive",
+				Description: "Archive the CTF this many days after the end, 0 to never archive it",
+			},
+		},
+	},
+	discord.SlashCommandCreate{
+		Name:        "archive",
+		Description: "[admin] Moves the CTF to the archive, where its channels are read only.",
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionBool{
+				Name:        "keep_role",
+				Description: "Whether players can still read the channels, defaults to true",
+			},
+		},
+	},
+	discord.SlashCommandCreate{
+		Name:        "unarchive",
+		Description: "[admin] Brings a CTF back from the archive.",
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionString{
+				Name:        "name",
+				Description: "Name of the CTF",
+				Required:    true,
+			},
+		},
+	},
+	discord.SlashCommandCreate{
+		Name:        "doctor",
+		Description: "[admin] Checks that the CTFs in the database match the server.",
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionBool{
+				Name:        "repair",
+				Description: "Fix the problems found, when possible",
+			},
+		},
+	},
+	discord.SlashCommandCreate{
+		Name:        "export",
+		Description: "Exports the channels of the CTF to Markdown, for writeups.",
+	},
 }
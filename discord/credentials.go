@@ -0,0 +1,96 @@
+package discord
+
+import (
+	"context"
+	"slices"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/havce/ctfbot"
+)
+
+func (s *Server) handleSetCredentials(event *handler.CommandEvent) error {
+	data := event.SlashCommandInteractionData()
+
+	ctf, err := s.ctfFromChannel(event.Channel().ID())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	creds := &ctfbot.Credentials{
+		CTFID:    ctf.ID,
+		Platform: data.String("platform"),
+		URL:      data.String("url"),
+		TeamName: data.String("team"),
+		Token:    data.String("token"),
+		Password: data.String("password"),
+	}
+
+	if !isValidURL(creds.URL) {
+		return Error(event, ctfbot.Errorf(ctfbot.EINVALID, "`%s` is not a valid URL.", creds.URL))
+	}
+
+	if err := s.CredentialsService.SetCredentials(context.TODO(), creds); err != nil {
+		return Error(event, err)
+	}
+
+	Respond(event, "Credentials saved", "Players can now see them with `/creds show`.")
+	return nil
+}
+
+func (s *Server) handleShowCredentials(event *handler.CommandEvent) error {
+	ctf, err := s.ctfFromChannel(event.Channel().ID())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	// Only players of the CTF are allowed to see the credentials.
+	roleID, err := snowflake.Parse(ctf.RoleID)
+	if err != nil {
+		return Error(event, err)
+	}
+	if event.Member() == nil || !slices.Contains(event.Member().RoleIDs, roleID) {
+		return Error(event, ctfbot.Errorf(ctfbot.EUNAUTHORIZED, "Only players of `%s` can see its credentials.", ctf.Name))
+	}
+
+	creds, err := s.CredentialsService.FindCredentialsByCTFID(context.TODO(), ctf.ID)
+	if err != nil {
+		return Error(event, err)
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle(":key: "+ctf.Name+" credentials").
+		SetColor(ColorBlurple).
+		SetURL(creds.URL).
+		AddField("URL", creds.URL, false)
+	if creds.TeamName != "" {
+		embed.AddField("Team", "`"+creds.TeamName+"`", true)
+	}
+	if creds.Password != "" {
+		embed.AddField("Password", "||`"+creds.Password+"`||", true)
+	}
+	if creds.Token != "" {
+		embed.AddField("Token", "||`"+creds.Token+"`||", false)
+	}
+
+	// The command was deferred as ephemeral, so only the caller sees this.
+	_, err = event.CreateFollowupMessage(discord.NewMessageCreateBuilder().
+		SetEmbeds(embed.Build()).
+		SetEphemeral(true).
+		Build())
+	return err
+}
+
+// platformForCTF returns a client for the scoring platform ctf is linked to.
+// It fails with ENOTFOUND if the CTF isn't linked to a supported platform.
+func (s *Server) platformForCTF(ctx context.Context, ctf *ctfbot.CTF) (ctfbot.Platform, error) {
+	creds, err := s.CredentialsService.FindCredentialsByCTFID(ctx, ctf.ID)
+	if err != nil {
+		return nil, err
+	} else if creds.Platform == "" {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "`%s` is not linked to a supported platform.", ctf.Name)
+	}
+
+	return newPlatform(creds.Platform, creds.URL, creds.Token, "")
+}
@@ -8,26 +8,28 @@ import (
 	"strconv"
 	"strings"
 	"time"
-	"unicode/utf8"
 
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/handler"
 	"github.com/disgoorg/snowflake/v2"
 	"github.com/havce/ctfbot"
-)
-
-const (
-	flagEmoji  = "🚩"
-	bloodEmoji = "🩸"
+	"github.com/havce/ctfbot/ctftime"
 )
 
 const (
 	DefaultChannelPrivileges = discord.PermissionsAllText | discord.PermissionsAllVoice |
 		discord.PermissionUseApplicationCommands | discord.PermissionAddReactions | discord.PermissionAttachFiles | discord.PermissionEmbedLinks
+	ReadOnlyChannelPrivileges = discord.PermissionViewChannel | discord.PermissionReadMessageHistory
 )
 
 func (s *Server) handleCommandNewCTF(event *handler.CommandEvent) error {
-	ctfName := s.extractCTFName(event.SlashCommandInteractionData().String("name"))
+	name := event.SlashCommandInteractionData().String("name")
+
+	// Link the CTF to its CTFTime event, if we were given one.
+	ctfName, eventID := name, 0
+	if ctftimeEvent := s.findCTFTimeEvent(name); ctftimeEvent != nil {
+		ctfName, eventID = ctftimeEvent.Title, ctftimeEvent.ID
+	}
 
 	urlEncodedCTFName := url.PathEscape(ctfName)
 
@@ -37,14 +39,19 @@ func (s *Server) handleCommandNewCTF(event *handler.CommandEvent) error {
 		return Error(event, ctfbot.Errorf(ctfbot.ECONFLICT, "A CTF with the same name has already been created."))
 	}
 
+	description := fmt.Sprintf("Would you like to create a new CTF named `%s`?", ctfName)
+	if eventID != 0 {
+		description += "\nIt will be linked to its CTFTime event."
+	}
+
 	_, err = event.CreateFollowupMessage(discord.NewMessageCreateBuilder().
 		SetEmbeds(discord.NewEmbedBuilder().
 			SetColor(ColorBlurple).
 			SetTitle(":white_check_mark: Confirm creation").
-			SetDescriptionf("Would you like to create a new CTF named `%s`?", ctfName).
+			SetDescription(description).
 			Build()).
 		AddActionRow(
-			discord.NewSuccessButton("Yes, create it", fmt.Sprintf("/new/%s/create", urlEncodedCTFName)),
+			discord.NewSuccessButton("Yes, create it", fmt.Sprintf("/new/%s/%d/create", urlEncodedCTFName, eventID)),
 		).
 		Build(),
 	)
@@ -55,18 +62,16 @@ func (s *Server) handleCommandNewCTF(event *handler.CommandEvent) error {
 	return err
 }
 
-func (s *Server) extractCTFName(name string) string {
-	ctftimeEvent := 0
-
-	var err error
-
+// findCTFTimeEvent returns the CTFTime event name refers to, either by URL or
+// by ID. Returns nil if name is not a CTFTime event.
+func (s *Server) findCTFTimeEvent(name string) *ctftime.Event {
 	numberCandidate := name
 	// Try to parse CTFTime URL.
 	if strings.Contains(name, "ctftime.org") {
 		u, err := url.Parse(name)
 		if err != nil {
 			s.client.Logger().Warn("Couldn't parse URL", "err", err)
-			return name
+			return nil
 		}
 
 		ep := u.EscapedPath()
@@ -74,32 +79,32 @@ func (s *Server) extractCTFName(name string) string {
 
 		i := slices.Index(pathComponents, "event")
 		if i == -1 || i+1 >= len(pathComponents) {
-			return name
+			return nil
 		}
 		numberCandidate = pathComponents[i+1]
 	}
 
-	ctftimeEvent, err = strconv.Atoi(numberCandidate)
+	ctftimeEvent, err := strconv.Atoi(numberCandidate)
 	if err != nil {
-		return name
+		return nil
 	}
 
 	event, err := s.CTFTimeClient.FindEventByID(context.TODO(), ctftimeEvent)
 	if err != nil {
 		s.client.Logger().Warn("Couldn't fetch ctftime information", "err", err)
-		return name
+		return nil
 	}
 
-	return event.Title
+	return event
 }
 
 func (s *Server) handleCommandDeleteCTF(event *handler.CommandEvent) error {
-	ctf, err := s.parentChannel(event.Channel().ID())
+	ctf, err := s.ctfFromChannel(event.Channel().ID())
 	if err != nil {
 		return Error(event, err)
 	}
 
-	ctfName := ctf.Name()
+	ctfName := ctf.Name
 
 	_, err = event.CreateFollowupMessage(discord.NewMessageCreateBuilder().
 		SetEmbeds(discord.NewEmbedBuilder().
@@ -121,131 +126,211 @@ func (s *Server) handleCommandDeleteCTF(event *handler.CommandEvent) error {
 }
 
 func (s *Server) handleDeleteCTF(event *handler.ComponentEvent) error {
-	ctf, err := s.parentChannel(event.Channel().ID())
+	ctf, err := s.ctfFromChannel(event.Channel().ID())
 	if err != nil {
 		return Error(event, err)
 	}
 
-	ctfName := ctf.Name()
+	if err := s.deleteCTF(context.TODO(), ctf); err != nil {
+		return Error(event, err)
+	}
 
-	siblings := []snowflake.ID{}
-	s.client.Caches().ChannelsForEach(func(channel discord.GuildChannel) {
-		if channel.ParentID() == nil {
-			return
-		}
+	Respond(event, "Deletion completed", fmt.Sprintf("You successfully deleted `%s`", ctf.Name))
+	return nil
+}
 
-		if *channel.ParentID() != ctf.ID() {
-			return
+// deleteCTF deletes the channels and the role of ctf, then ctf itself. The
+// CTF is marked as being deleted beforehand, so that a deletion failing
+// half-way is resumed by runSchedules. What is already gone is skipped.
+func (s *Server) deleteCTF(ctx context.Context, ctf *ctfbot.CTF) error {
+	if ctf.Status != ctfbot.CTFStatusDeleting {
+		status, canJoin := ctfbot.CTFStatusDeleting, false
+		_, err := s.CTFService.UpdateCTF(ctx, ctf.Name, ctfbot.CTFUpdate{
+			Status:  &status,
+			CanJoin: &canJoin,
+		})
+		if err != nil {
+			return err
 		}
+	}
 
-		siblings = append(siblings, channel.ID())
-	})
+	guildID, err := snowflake.Parse(s.GuildID)
+	if err != nil {
+		return err
+	}
 
-	// Delete all channels.
-	for _, channel := range siblings {
-		if err := s.client.Rest().DeleteChannel(channel); err != nil {
-			return Error(event, err)
+	// Delete all channels, then their category.
+	category, err := s.categoryChannel(ctf)
+	if err == nil {
+		for _, channel := range s.childChannels(category.ID()) {
+			if err := s.client.Rest().DeleteChannel(channel.ID()); err != nil && !isNotFound(err) {
+				return err
+			}
 		}
+
+		if err := s.client.Rest().DeleteChannel(category.ID()); err != nil && !isNotFound(err) {
+			return err
+		}
+	} else if ctfbot.ErrorCode(err) != ctfbot.ENOTFOUND {
+		return err
 	}
 
-	// Delete parent.
-	if err := s.client.Rest().DeleteChannel(ctf.ID()); err != nil {
-		return Error(event, err)
+	roleID, err := snowflake.Parse(ctf.RoleID)
+	if err != nil {
+		return err
 	}
 
-	// Fetch the CTF from DB to get the role ID to delete.
-	ctfFromDB, err := s.CTFService.FindCTFByName(context.TODO(), ctfName)
+	// Delete the role from Discord.
+	if err := s.client.Rest().DeleteRole(guildID, roleID); err != nil && !isNotFound(err) {
+		return err
+	}
+
+	// Delete the CTF from db.
+	return s.CTFService.DeleteCTF(ctx, ctf.Name)
+}
+
+func (s *Server) handleCreateCTF(event *handler.ComponentEvent) error {
+	ctf, err := url.PathUnescape(event.Vars["ctf"])
 	if err != nil {
 		return Error(event, err)
 	}
 
-	roleID, err := snowflake.Parse(ctfFromDB.RoleID)
+	eventID, err := strconv.Atoi(event.Vars["event"])
 	if err != nil {
 		return Error(event, err)
 	}
 
-	// Delete the role from Discord.
-	if err := s.client.Rest().DeleteRole(*event.GuildID(), roleID); err != nil {
+	if _, err := s.newCTF(context.TODO(), *event.GuildID(), ctf, eventID); err != nil {
 		return Error(event, err)
 	}
 
-	// Delete the CTF from db.
-	if err := s.CTFService.DeleteCTF(context.TODO(), ctfName); err != nil {
-		return Error(event, err)
+	_, err = event.UpdateFollowupMessage(
+		event.Message.ID,
+		discord.NewMessageUpdateBuilder().
+			SetEmbeds(discord.NewEmbedBuilder().
+				SetColor(ColorGreen).
+				SetDescriptionf("CTF `%s` was successfully created!", ctf).
+				Build()).
+			ClearContainerComponents().
+			Build())
+	if err != nil {
+		return err
 	}
 
-	Respond(event, "Deletion completed", fmt.Sprintf("You successfully deleted `%s`", ctfName))
-	return nil
+	return event.DeleteInteractionResponse()
 }
 
-func (s *Server) handleCreateCTF(event *handler.ComponentEvent) error {
-	ctf, err := url.PathUnescape(event.Vars["ctf"])
-	if err != nil {
-		return Error(event, err)
+// newCTF creates the CTF named name, linked to the CTFTime event eventID
+// unless it is zero.
+func (s *Server) newCTF(ctx context.Context, guildID snowflake.ID, name string, eventID int) (*ctfbot.CTF, error) {
+	newCTF := &ctfbot.CTF{
+		Name:    name,
+		Start:   time.Now(),
+		CanJoin: true,
+	}
+
+	// Fetch the CTFTime event again, it might have been updated since /new.
+	if eventID != 0 {
+		ctftimeEvent, err := s.CTFTimeClient.FindEventByID(ctx, eventID)
+		if err != nil {
+			return nil, err
+		}
+		linkCTFTimeEvent(newCTF, ctftimeEvent)
+
+		// We only know when to close and archive CTFs from CTFTime.
+		if s.CloseAfter > 0 {
+			newCTF.CloseAt = newCTF.Start.Add(s.CloseAfter)
+		}
+		if s.ArchiveAfter > 0 && !newCTF.Finish.IsZero() {
+			newCTF.ArchiveAt = newCTF.Finish.Add(s.ArchiveAfter)
+		}
 	}
 
 	// Check again if CTF is already present with the same name.
-	_, err = s.CTFService.FindCTFByName(context.TODO(), ctf)
+	_, err := s.CTFService.FindCTFByName(ctx, name)
 	if err == nil {
-		return Error(event, ctfbot.Errorf(ctfbot.ECONFLICT, "A CTF with the same name has already been created."))
+		return nil, ctfbot.Errorf(ctfbot.ECONFLICT, "A CTF with the same name has already been created.")
 	}
 
+	if err := s.createCTF(ctx, guildID, newCTF); err != nil {
+		return nil, err
+	}
+	return newCTF, nil
+}
+
+// createCTF creates the role and the channels of ctf, then ctf itself. If
+// any step fails, what was created until then is deleted, so that nothing is
+// left behind and the CTF can be created again.
+func (s *Server) createCTF(ctx context.Context, guildID snowflake.ID, ctf *ctfbot.CTF) (err error) {
+	sg := &saga{logger: s.client.Logger()}
+	defer func() {
+		if err != nil {
+			sg.rollback()
+		}
+	}()
+
 	// Create role with CTF name.
 	role, err := s.client.Rest().CreateRole(
-		*event.GuildID(),
+		guildID,
 		discord.RoleCreate{
-			Name:        ctf,
+			Name:        ctf.Name,
 			Mentionable: true,
 		},
 	)
 	if err != nil {
-		return Error(event, err)
+		return err
+	}
+	sg.undo("role", func() error {
+		return s.client.Rest().DeleteRole(guildID, role.ID)
+	})
+
+	if _, err := s.createCTFChannels(sg, guildID, ctf, role.ID); err != nil {
+		return err
 	}
 
+	// Parse the role.ID as uint64 and then convert
+	// as string.
+	ctf.RoleID = strconv.FormatUint(uint64(role.ID), 10)
+	if err := s.CTFService.CreateCTF(ctx, ctf); err != nil {
+		return err
+	}
+	sg.undo("database", func() error {
+		return s.CTFService.DeleteCTF(ctx, ctf.Name)
+	})
+
+	return s.scheduleReminders(ctx, ctf)
+}
+
+// createCTFChannels creates the category of ctf, along with its registration
+// and general channels, where roleID is the role of the players. Their IDs
+// are stored in ctf. Created channels are recorded in sg, to be deleted if a
+// later step fails.
+func (s *Server) createCTFChannels(sg *saga, guildID snowflake.ID, ctf *ctfbot.CTF, roleID snowflake.ID) (discord.GuildChannel, error) {
 	// Create category with the name of the CTF.
-	category, err := s.client.Rest().CreateGuildChannel(
-		*event.GuildID(),
-		discord.GuildCategoryChannelCreate{
-			Name:     ctf,
-			Position: 1,
-			PermissionOverwrites: []discord.PermissionOverwrite{
-				discord.RolePermissionOverwrite{
-					RoleID: *event.GuildID(),
-					Deny:   discord.PermissionsAll,
-					Allow:  discord.PermissionViewChannel,
-				},
-				discord.RolePermissionOverwrite{
-					RoleID: role.ID,
-					Allow:  discord.PermissionsAllText | discord.PermissionsAllVoice,
-				},
-			},
-		})
+	category, err := s.createCTFCategory(guildID, ctf.Name, roleID)
 	if err != nil {
-		return Error(event, err)
+		return nil, err
 	}
-
-	var everyoneID *snowflake.ID
-	s.client.Caches().RolesForEach(*event.GuildID(), func(role discord.Role) {
-		if role.Name == "@everyone" {
-			everyoneID = &role.ID
-		}
+	sg.undo("category", func() error {
+		return s.client.Rest().DeleteChannel(category.ID())
 	})
 
-	// Create registration channel inside category.
+	// Create registration channel inside category. The @everyone role
+	// shares its ID with the guild.
 	regChannel, err := s.client.Rest().CreateGuildChannel(
-		*event.GuildID(),
+		guildID,
 		discord.GuildTextChannelCreate{
 			Name:     s.RegistrationChannel,
-			Topic:    fmt.Sprintf("%s player registration", ctf),
+			Topic:    fmt.Sprintf("%s player registration", ctf.Name),
 			ParentID: category.ID(),
 			PermissionOverwrites: []discord.PermissionOverwrite{
 				discord.RolePermissionOverwrite{
-					RoleID: *everyoneID,
+					RoleID: guildID,
 					Allow:  discord.PermissionViewChannel | discord.PermissionReadMessageHistory,
 					Deny:   discord.PermissionsAll,
 				},
 				discord.RolePermissionOverwrite{
-					RoleID: role.ID,
+					RoleID: roleID,
 					Allow:  discord.PermissionViewChannel | discord.PermissionReadMessageHistory,
 					Deny:   discord.PermissionsAll,
 				},
@@ -253,70 +338,115 @@ func (s *Server) handleCreateCTF(event *handler.ComponentEvent) error {
 		},
 	)
 	if err != nil {
-		return Error(event, err)
+		return nil, err
 	}
+	sg.undo("registration channel", func() error {
+		return s.client.Rest().DeleteChannel(regChannel.ID())
+	})
 
-	// Create recruitment message in registration text channel.
-	_, err = s.client.Rest().CreateMessage(regChannel.ID(), discord.NewMessageCreateBuilder().
-		SetEmbeds(discord.NewEmbedBuilder().
-			SetColor(ColorBlurple).
-			SetDescriptionf("Press the button to join `%s`", ctf).
-			Build()).
+	// Create recruitment message in registration text channel. It goes
+	// away with the channel.
+	message, err := s.client.Rest().CreateMessage(regChannel.ID(), discord.NewMessageCreateBuilder().
+		SetEmbeds(messageEmbedRegistration(ctf)).
 		AddActionRow(
-			discord.NewPrimaryButton(fmt.Sprintf("Join %s", ctf), fmt.Sprintf("/join/%s", url.PathEscape(ctf))),
+			discord.NewPrimaryButton(fmt.Sprintf("Join %s", ctf.Name), fmt.Sprintf("/join/%s", url.PathEscape(ctf.Name))),
 		).Build())
 	if err != nil {
-		return Error(event, err)
+		return nil, err
 	}
 
 	// Create general channel inside category.
-	_, err = s.client.Rest().CreateGuildChannel(
-		*event.GuildID(),
+	general, err := s.client.Rest().CreateGuildChannel(
+		guildID,
 		discord.GuildTextChannelCreate{
 			Name:     s.GeneralChannel,
 			ParentID: category.ID(),
 			PermissionOverwrites: []discord.PermissionOverwrite{
 				discord.RolePermissionOverwrite{
-					RoleID: *everyoneID,
+					RoleID: guildID,
 					Deny:   discord.PermissionsAll,
 				},
 				discord.RolePermissionOverwrite{
-					RoleID: role.ID,
+					RoleID: roleID,
 					Allow:  DefaultChannelPrivileges,
 				},
 			},
 		},
 	)
 	if err != nil {
-		return Error(event, err)
+		return nil, err
 	}
-
-	err = s.CTFService.CreateCTF(context.TODO(), &ctfbot.CTF{
-		Name:  ctf,
-		Start: time.Now(),
-		// Parse the role.ID as uint64 and then convert
-		// as string.
-		RoleID:  strconv.FormatUint(uint64(role.ID), 10),
-		CanJoin: true,
+	sg.undo("general channel", func() error {
+		return s.client.Rest().DeleteChannel(general.ID())
 	})
-	if err != nil {
-		return Error(event, err)
-	}
 
-	_, err = event.UpdateFollowupMessage(
-		event.Message.ID,
-		discord.NewMessageUpdateBuilder().
-			SetEmbeds(discord.NewEmbedBuilder().
-				SetColor(ColorGreen).
-				SetDescriptionf("CTF `%s` was successfully created!", ctf).
-				Build()).
-			ClearContainerComponents().
-			Build())
-	if err != nil {
-		return err
+	ctf.CategoryID = category.ID().String()
+	ctf.RegistrationChannelID = regChannel.ID().String()
+	ctf.RegistrationMessageID = message.ID.String()
+	ctf.GeneralChannelID = general.ID().String()
+
+	return category, nil
+}
+
+// createCTFCategory creates the category of a CTF, where only its players
+// can write.
+func (s *Server) createCTFCategory(guildID snowflake.ID, name string, roleID snowflake.ID) (discord.GuildChannel, error) {
+	return s.client.Rest().CreateGuildChannel(
+		guildID,
+		discord.GuildCategoryChannelCreate{
+			Name:     name,
+			Position: 1,
+			PermissionOverwrites: []discord.PermissionOverwrite{
+				discord.RolePermissionOverwrite{
+					RoleID: guildID,
+					Deny:   discord.PermissionsAll,
+					Allow:  discord.PermissionViewChannel,
+				},
+				discord.RolePermissionOverwrite{
+					RoleID: roleID,
+					Allow:  discord.PermissionsAllText | discord.PermissionsAllVoice,
+				},
+			},
+		})
+}
+
+// linkCTFTimeEvent copies the information of the CTFTime event to ctf.
+func linkCTFTimeEvent(ctf *ctfbot.CTF, event *ctftime.Event) {
+	ctf.CTFTimeID = event.ID
+	ctf.CTFTimeURL = event.CTFTimeURL
+	ctf.Start = event.Start
+	ctf.Finish = event.Finish
+	ctf.Weight = event.Weight
+	ctf.Format = event.Format
+	ctf.URL = event.URL
+}
+
+// messageEmbedRegistration builds the embed inviting players to join ctf.
+func messageEmbedRegistration(ctf *ctfbot.CTF) discord.Embed {
+	embed := discord.NewEmbedBuilder().
+		SetColor(ColorBlurple).
+		SetDescriptionf("Press the button to join `%s`", ctf.Name)
+
+	// Only CTFs linked to CTFTime have more to show.
+	if ctf.CTFTimeID == 0 {
+		return embed.Build()
 	}
 
-	return event.DeleteInteractionResponse()
+	embed.SetTitle(ctf.Name).
+		AddField("Starts", formatTime(&ctf.Start), true).
+		AddField("Ends", formatTime(&ctf.Finish), true).
+		AddField("Rating", strconv.FormatFloat(ctf.Weight, 'f', 2, 64), true)
+	if ctf.Format != "" {
+		embed.AddField("Format", ctf.Format, true)
+	}
+	if isValidURL(ctf.URL) {
+		embed.SetURL(ctf.URL).
+			AddField("CTF link", ctf.URL, false)
+	}
+	if isValidURL(ctf.CTFTimeURL) {
+		embed.AddField("CTFTime", ctf.CTFTimeURL, false)
+	}
+	return embed.Build()
 }
 
 func (s *Server) handleJoinCTF(event *handler.ComponentEvent) error {
@@ -367,13 +497,13 @@ func (s *Server) handleJoinCTF(event *handler.ComponentEvent) error {
 
 func (s *Server) handleUpdateCanJoin(canJoin bool) func(event *handler.CommandEvent) error {
 	return func(event *handler.CommandEvent) error {
-		parentChannel, err := s.parentChannel(event.Channel().ID())
+		// If you're not inside a CTF it will output a CTF not found error.
+		ctf, err := s.ctfFromChannel(event.Channel().ID())
 		if err != nil {
 			return Error(event, err)
 		}
 
-		// If you're not inside a CTF it will output a CTF not found error.
-		_, err = s.CTFService.UpdateCTF(context.TODO(), parentChannel.Name(),
+		_, err = s.CTFService.UpdateCTF(context.TODO(), ctf.Name,
 			ctfbot.CTFUpdate{
 				CanJoin: &canJoin,
 			})
@@ -388,147 +518,7 @@ func (s *Server) handleUpdateCanJoin(canJoin bool) func(event *handler.CommandEv
 
 		Respond(event, "Change registration status",
 			fmt.Sprintf("You successfully %s registrations for `%s`.",
-				status, parentChannel.Name()))
+				status, ctf.Name))
 		return nil
 	}
 }
-
-func (s *Server) handleFlag(blood bool) func(event *handler.CommandEvent) error {
-	return func(event *handler.CommandEvent) error {
-		prefix := flagEmoji
-		if blood {
-			prefix = bloodEmoji
-		}
-
-		if !s.flagAllowed(event.Channel().Name()) {
-			return Error(event, ctfbot.Errorf(
-				ctfbot.EINVALID, "You cannot flag here."))
-		}
-
-		// Check if someone has already flagged this.
-		if utf8.RuneCountInString(event.Channel().Name()) > 0 {
-			// Decode first rune. We don't care about the byte length.
-			c, _ := utf8.DecodeRuneInString(event.Channel().Name())
-
-			blocklist := []string{flagEmoji, bloodEmoji}
-			// Check against blocklist.
-			if slices.Contains(blocklist, string(c)) {
-				return Error(event, ctfbot.Errorf(ctfbot.EINVALID, "Somebody has already %s this.", prefix))
-			}
-		}
-
-		// Prepend the prefix emoji.
-		newName := prefix + " " + event.Channel().Name()
-
-		// Update channel name with the prefixed emoji of flag or blood.
-		_, err := s.client.Rest().UpdateChannel(event.Channel().ID(), discord.GuildTextChannelUpdate{
-			Name: &newName,
-		})
-		if err != nil {
-			return Error(event, err)
-		}
-
-		// Delete response.
-		if err := event.DeleteInteractionResponse(); err != nil {
-			return err
-		}
-
-		// Show everyone who flagged this! Publicly post this.
-		_, err = s.client.Rest().CreateMessage(event.Channel().ID(),
-			discord.NewMessageCreateBuilder().
-				SetEphemeral(false).
-				SetEmbeds(messageEmbedSuccess(prefix+" New flag!",
-					fmt.Sprintf("%s! %s has flagged `%s`.",
-						cheer(), event.User().String(), event.Channel().Name()))).
-				Build())
-		return err
-	}
-}
-
-func (s *Server) handleNewChal(event *handler.CommandEvent) error {
-	chalName := event.SlashCommandInteractionData().String("name")
-
-	// Get parent ID of the current channel.
-	parentChannel, _ := s.parentChannel(event.Channel().ID())
-
-	// Check if there's another sibling channel with the same name.
-	found := false
-	s.client.Caches().ChannelsForEach(func(channel discord.GuildChannel) {
-		// First check if it is our sibling.
-		if channel.ParentID() == nil || *channel.ParentID() != parentChannel.ID() {
-			return
-		}
-
-		// Replace blood and flag indicators. We don't want to add an
-		// already solved challenge.
-		cleanChanName := channel.Name()
-
-		// Append "-" to emojis, because Discord replaces spaces with dashes.
-		cleanChanName = strings.NewReplacer(
-			flagEmoji+"-", "",
-			bloodEmoji+"-", "").Replace(cleanChanName)
-
-		if chalName == cleanChanName {
-			found = true
-			return
-		}
-	})
-
-	// If so, return an error.
-	if found {
-		return Error(event, ctfbot.Errorf(
-			ctfbot.ECONFLICT, "Somebody has already created `%s`.", chalName))
-	}
-
-	// We already validated the existence of parentChannel in the middleware.
-	// If someone has already deleted them in the meantime, well, this sucks.
-	// But the error would show up in a later call.
-	ctf, _ := s.CTFService.FindCTFByName(context.TODO(), parentChannel.Name())
-
-	// Search @everyone role ID.
-	var everyoneID *snowflake.ID
-	s.client.Caches().RolesForEach(*event.GuildID(), func(role discord.Role) {
-		if role.Name == "@everyone" {
-			everyoneID = &role.ID
-		}
-	})
-
-	roleID, err := snowflake.Parse(ctf.RoleID)
-	if err != nil {
-		return Error(event, err)
-	}
-
-	role, found := s.client.Caches().Role(*event.GuildID(), roleID)
-	if !found {
-		return Error(event, ctfbot.Errorf(ctfbot.EINTERNAL, "Couldn't find player role for `%s`. Maybe it was deleted?", ctf.Name))
-	}
-
-	// Create the channel with our custom permissions.
-	// No one but the current role members should see the channel.
-	channel, err := s.client.Rest().CreateGuildChannel(*event.GuildID(), discord.GuildTextChannelCreate{
-		Name:     chalName,
-		ParentID: parentChannel.ID(),
-		PermissionOverwrites: []discord.PermissionOverwrite{
-			discord.RolePermissionOverwrite{
-				RoleID: *everyoneID,
-				Deny:   discord.PermissionsAll,
-			},
-			discord.RolePermissionOverwrite{
-				RoleID: role.ID,
-				Allow:  DefaultChannelPrivileges,
-			},
-		},
-	})
-	if err != nil {
-		return Error(event, err)
-	}
-
-	_, err = s.client.Rest().CreateMessage(channel.ID(), discord.NewMessageCreateBuilder().SetEmbeds(messageEmbedSuccess(
-		"New challenge!", fmt.Sprintf("%s has created `%s`", event.User().String(), chalName))).Build())
-	if err != nil {
-		return Error(event, err)
-	}
-
-	Respond(event, "New channel created", fmt.Sprintf("Successfully added channel `%s`.", chalName))
-	return err
-}
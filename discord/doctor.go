@@ -0,0 +1,356 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/havce/ctfbot"
+)
+
+// Embed descriptions can't be longer than 4096 characters.
+const maxEmbedDescriptionLength = 4096
+
+// problem is an inconsistency between the database and the guild.
+type problem struct {
+	// Name of the CTF, or of the category, with the problem.
+	subject     string
+	description string
+
+	// Fixes the problem. Nil if it has to be fixed by hand.
+	repair func(ctx context.Context) error
+
+	// Whether the repair can run unattended. Safe repairs only store in the
+	// database what's found in the guild, the others change the guild and
+	// wait for an admin to run them.
+	safe bool
+}
+
+func (s *Server) handleDoctor(event *handler.CommandEvent) error {
+	repair, _ := event.SlashCommandInteractionData().OptBool("repair")
+
+	problems, err := s.diagnose(context.TODO())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	if len(problems) == 0 {
+		Respond(event, ":stethoscope: All good", "The database and the server agree on every CTF.")
+		return nil
+	}
+
+	lines := make([]string, 0, len(problems))
+	for _, p := range problems {
+		line := fmt.Sprintf("- **%s**: %s", p.subject, p.description)
+		switch {
+		case p.repair == nil:
+			line += " _Fix it by hand._"
+		case !repair:
+			line += " _Run `/doctor repair:True` to fix it._"
+		default:
+			if err := p.repair(context.TODO()); err != nil {
+				line += fmt.Sprintf(" _Couldn't fix it: %s_", ctfbot.ErrorMessage(err))
+			} else {
+				line += " _Fixed._"
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	_, err = event.CreateFollowupMessage(discord.NewMessageCreateBuilder().
+		SetEmbeds(discord.NewEmbedBuilder().
+			SetTitle(fmt.Sprintf(":stethoscope: Found %d problems", len(problems))).
+			SetColor(ColorYellow).
+			SetDescription(truncate(strings.Join(lines, "\n"), maxEmbedDescriptionLength)).
+			Build()).
+		Build())
+	return err
+}
+
+// reconcile safely repairs the inconsistencies between the database and the
+// guild. The other ones are only logged, they're left to /doctor.
+func (s *Server) reconcile(ctx context.Context) {
+	problems, err := s.diagnose(ctx)
+	if err != nil {
+		s.client.Logger().Error("Couldn't check the CTFs", "err", err)
+		return
+	}
+
+	for _, p := range problems {
+		if p.repair == nil || !p.safe {
+			s.client.Logger().Warn("Inconsistency found", "subject", p.subject, "problem", p.description)
+			continue
+		}
+
+		if err := p.repair(ctx); err != nil {
+			s.client.Logger().Warn("Couldn't repair inconsistency", "subject", p.subject, "problem", p.description, "err", err)
+			continue
+		}
+		s.client.Logger().Info("Repaired inconsistency", "subject", p.subject, "problem", p.description)
+	}
+}
+
+// diagnose compares the CTFs in the database with the roles and categories
+// of the guild. Archived CTFs, and the ones being deleted, are left alone.
+func (s *Server) diagnose(ctx context.Context) ([]problem, error) {
+	guildID, err := snowflake.Parse(s.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctfs, _, err := s.CTFService.FindCTFs(ctx, ctfbot.CTFFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	problems := []problem{}
+
+	// Categories belonging to a CTF, any other CTF-looking category is an
+	// orphan.
+	known := map[snowflake.ID]bool{}
+	for _, ctf := range ctfs {
+		category, err := s.categoryChannel(ctf)
+		if err != nil && ctfbot.ErrorCode(err) != ctfbot.ENOTFOUND {
+			return nil, err
+		}
+
+		if ctf.Status != ctfbot.CTFStatusActive {
+			if category != nil {
+				known[category.ID()] = true
+			}
+			continue
+		}
+
+		roleID, _ := snowflake.Parse(ctf.RoleID)
+		if _, found := s.client.Caches().Role(guildID, roleID); !found {
+			problems = append(problems, problem{
+				subject:     ctf.Name,
+				description: "The role of the players is missing. A new one is created, players have to join again.",
+				repair:      s.repairRole(guildID, ctf.ID),
+			})
+		}
+
+		// CTFs created before the category ID was stored are found by name,
+		// so look for a category their players can access if it's been
+		// renamed.
+		if category == nil && ctf.CategoryID == "" {
+			category = s.categoryByRole(roleID)
+		}
+
+		if category == nil {
+			problems = append(problems, problem{
+				subject:     ctf.Name,
+				description: "The category is missing. It's created again, with the registration and general channels.",
+				repair:      s.repairCategory(guildID, ctf.ID),
+			})
+			continue
+		}
+		known[category.ID()] = true
+
+		if ctf.CategoryID == "" {
+			problems = append(problems, problem{
+				subject:     ctf.Name,
+				description: "The IDs of the channels aren't stored, they're looked up.",
+				repair:      s.repairChannelIDs(ctf.ID, category.ID()),
+				safe:        true,
+			})
+		}
+
+		// Players still tell CTFs apart by the name of their category.
+		if category.Name() != ctf.Name {
+			problems = append(problems, problem{
+				subject:     ctf.Name,
+				description: fmt.Sprintf("The category was renamed to `%s`, it's renamed back.", category.Name()),
+				repair:      s.repairCategoryName(category.ID(), ctf.Name),
+			})
+		}
+	}
+
+	for _, category := range s.categories() {
+		if known[category.ID()] || category.Name() == s.ArchiveCategory {
+			continue
+		}
+
+		// Only complain about the categories created by us.
+		_, regErr := s.childChannelByName(category.ID(), s.RegistrationChannel)
+		_, generalErr := s.childChannelByName(category.ID(), s.GeneralChannel)
+		if regErr != nil || generalErr != nil {
+			continue
+		}
+
+		problems = append(problems, problem{
+			subject:     category.Name(),
+			description: "The category looks like a CTF, but there's no such CTF in the database.",
+		})
+	}
+
+	return problems, nil
+}
+
+// repairRole returns a repair that creates a new role for the players of
+// the CTF identified by ctfID, and gives it access to the CTF channels.
+func (s *Server) repairRole(guildID snowflake.ID, ctfID int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctf, err := s.findCTFByID(ctx, ctfID)
+		if err != nil {
+			return err
+		}
+
+		role, err := s.client.Rest().CreateRole(guildID, discord.RoleCreate{
+			Name:        ctf.Name,
+			Mentionable: true,
+		})
+		if err != nil {
+			return err
+		}
+
+		roleID := role.ID.String()
+		if _, err := s.CTFService.UpdateCTF(ctx, ctf.Name, ctfbot.CTFUpdate{RoleID: &roleID}); err != nil {
+			return err
+		}
+
+		// Without a category, there's nothing to give access to.
+		category, err := s.categoryChannel(ctf)
+		if ctfbot.ErrorCode(err) == ctfbot.ENOTFOUND {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		allow, deny := discord.PermissionsAllText|discord.PermissionsAllVoice, discord.Permissions(0)
+		err = s.client.Rest().UpdatePermissionOverwrite(category.ID(), role.ID, discord.RolePermissionOverwriteUpdate{
+			Allow: &allow,
+			Deny:  &deny,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, channel := range s.childChannels(category.ID()) {
+			// The registration channel is read only, as it was created.
+			allow, deny := discord.Permissions(DefaultChannelPrivileges), discord.Permissions(0)
+			if channel.Name() == s.RegistrationChannel {
+				allow, deny = ReadOnlyChannelPrivileges, discord.PermissionsAll
+			}
+			err = s.client.Rest().UpdatePermissionOverwrite(channel.ID(), role.ID, discord.RolePermissionOverwriteUpdate{
+				Allow: &allow,
+				Deny:  &deny,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// repairCategoryName returns a repair that gives back the category its name.
+func (s *Server) repairCategoryName(categoryID snowflake.ID, name string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := s.client.Rest().UpdateChannel(categoryID, discord.GuildCategoryChannelUpdate{
+			Name: &name,
+		})
+		return err
+	}
+}
+
+// repairCategory returns a repair that creates the category of the CTF
+// identified by ctfID again, with its registration and general channels.
+func (s *Server) repairCategory(guildID snowflake.ID, ctfID int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		// Fetch the CTF again, its role might have been repaired meanwhile.
+		ctf, err := s.findCTFByID(ctx, ctfID)
+		if err != nil {
+			return err
+		}
+
+		roleID, err := snowflake.Parse(ctf.RoleID)
+		if err != nil {
+			return err
+		}
+
+		sg := &saga{logger: s.client.Logger()}
+		if _, err := s.createCTFChannels(sg, guildID, ctf, roleID); err != nil {
+			sg.rollback()
+			return err
+		}
+
+		_, err = s.CTFService.UpdateCTF(ctx, ctf.Name, ctfbot.CTFUpdate{
+			CategoryID:            &ctf.CategoryID,
+			RegistrationChannelID: &ctf.RegistrationChannelID,
+			GeneralChannelID:      &ctf.GeneralChannelID,
+			RegistrationMessageID: &ctf.RegistrationMessageID,
+		})
+		if err != nil {
+			sg.rollback()
+		}
+		return err
+	}
+}
+
+// repairChannelIDs returns a repair that stores the IDs of the category
+// identified by categoryID, and of the channels and message created along
+// with it, in the CTF identified by ctfID.
+func (s *Server) repairChannelIDs(ctfID int, categoryID snowflake.ID) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctf, err := s.findCTFByID(ctx, ctfID)
+		if err != nil {
+			return err
+		}
+
+		upd := ctfbot.CTFUpdate{}
+		category := categoryID.String()
+		upd.CategoryID = &category
+
+		if channel, err := s.childChannelByName(categoryID, s.GeneralChannel); err == nil {
+			general := channel.ID().String()
+			upd.GeneralChannelID = &general
+		}
+
+		if channel, err := s.childChannelByName(categoryID, s.RegistrationChannel); err == nil {
+			registration := channel.ID().String()
+			upd.RegistrationChannelID = &registration
+
+			// The registration message is the first one we sent there.
+			messages, err := s.channelMessages(channel.ID())
+			if err != nil {
+				return err
+			}
+			for _, message := range messages {
+				if message.Author.ID == s.client.ID() {
+					messageID := message.ID.String()
+					upd.RegistrationMessageID = &messageID
+					break
+				}
+			}
+		}
+
+		_, err = s.CTFService.UpdateCTF(ctx, ctf.Name, upd)
+		return err
+	}
+}
+
+// categoryByRole returns the category where roleID has a permission
+// overwrite, or nil if there's none.
+func (s *Server) categoryByRole(roleID snowflake.ID) discord.GuildChannel {
+	for _, category := range s.categories() {
+		if _, ok := category.PermissionOverwrites().Role(roleID); ok {
+			return category
+		}
+	}
+	return nil
+}
+
+// categories returns every category of the guild.
+func (s *Server) categories() []discord.GuildChannel {
+	categories := []discord.GuildChannel{}
+	s.client.Caches().ChannelsForEach(func(channel discord.GuildChannel) {
+		if channel.Type() == discord.ChannelTypeGuildCategory {
+			categories = append(categories, channel)
+		}
+	})
+	return categories
+}
@@ -0,0 +1,344 @@
+package discord
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/havce/ctfbot"
+)
+
+const (
+	// Discord doesn't return more than 100 messages at once.
+	maxMessagesPerRequest = 100
+
+	// Discord refuses files bigger than 10 MB in servers without boosts.
+	// Keep some room for the rest of the message.
+	maxUploadSize = 8 << 20
+)
+
+func (s *Server) handleExport(event *handler.CommandEvent) error {
+	// Archived CTFs have no category anymore, their channels are in the
+	// archive.
+	ctf, err := s.ctfFromChannel(event.Channel().ID())
+	if err != nil {
+		ctf, err = s.ctfFromArchivedChannel(context.TODO(), event.Channel().ID())
+	}
+	if ctfbot.ErrorCode(err) == ctfbot.ENOTFOUND {
+		return Error(event, ctfbot.Errorf(ctfbot.ENOTFOUND, "This channel is not associated with a registered CTF."))
+	} else if err != nil {
+		return Error(event, err)
+	}
+
+	files, err := s.exportFiles(context.TODO(), ctf)
+	if err != nil {
+		return Error(event, err)
+	}
+
+	// Split the export in as many archives as needed to fit in a message.
+	parts, err := splitExport(files, maxUploadSize)
+	if err != nil {
+		return Error(event, err)
+	}
+
+	description := fmt.Sprintf("%s has exported the channels of `%s`. Happy writeups!", event.User().String(), ctf.Name)
+	if len(parts) > 1 {
+		description = fmt.Sprintf("%s has exported the channels of `%s` in %d parts. Happy writeups!", event.User().String(), ctf.Name, len(parts))
+	}
+
+	for i, part := range parts {
+		builder := discord.NewMessageCreateBuilder()
+		if i == 0 {
+			builder.SetEmbeds(messageEmbedSuccess(":package: Export completed", description))
+		}
+
+		name := exportFileName(ctf)
+		if len(parts) > 1 {
+			name = fmt.Sprintf("%s-%d.zip", strings.TrimSuffix(name, ".zip"), i+1)
+		}
+
+		_, err = s.client.Rest().CreateMessage(event.Channel().ID(), builder.
+			AddFile(name, "Markdown export of "+ctf.Name, bytes.NewReader(part)).
+			Build())
+		if err != nil {
+			return Error(event, err)
+		}
+	}
+
+	return event.DeleteInteractionResponse()
+}
+
+// ExportCTF writes a zip archive to w, with the message history of every
+// channel of the CTF named name as a Markdown file. Challenge channels come
+// with the challenge details and solves. Only the REST API is used, so that
+// the export also works without a gateway connection.
+func (s *Server) ExportCTF(ctx context.Context, name string, w io.Writer) error {
+	ctf, err := s.CTFService.FindCTFByName(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	files, err := s.exportFiles(ctx, ctf)
+	if err != nil {
+		return err
+	}
+	return writeExport(w, files)
+}
+
+// exportFile is a Markdown file of an export.
+type exportFile struct {
+	name string
+	data []byte
+}
+
+// exportFiles renders every text channel of ctf to Markdown, one file per
+// channel. The channels of archived CTFs are found in the archive.
+func (s *Server) exportFiles(ctx context.Context, ctf *ctfbot.CTF) ([]exportFile, error) {
+	guildID, err := snowflake.Parse(s.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	channels, err := s.client.Rest().GetGuildChannels(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctf.Status == ctfbot.CTFStatusArchived {
+		channels = s.archivedChannelsIn(ctf, channels)
+	} else {
+		// Find the category of the CTF first, then its channels. CTFs
+		// created before the category ID was stored are found by name.
+		categoryID, _ := snowflake.Parse(ctf.CategoryID)
+		for _, channel := range channels {
+			if categoryID == 0 && channel.Type() == discord.ChannelTypeGuildCategory && channel.Name() == ctf.Name {
+				categoryID = channel.ID()
+			}
+		}
+		if categoryID == 0 {
+			return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "Category of `%s` not found.", ctf.Name)
+		}
+
+		channels = slices.DeleteFunc(channels, func(channel discord.GuildChannel) bool {
+			return channel.ParentID() == nil || *channel.ParentID() != categoryID
+		})
+	}
+
+	e := &exporter{server: s, users: map[string]string{}}
+	files := []exportFile{}
+	for _, channel := range channels {
+		if channel.Type() != discord.ChannelTypeGuildText {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := e.exportChannel(ctx, channel, &buf); err != nil {
+			return nil, err
+		}
+
+		// Archived channels are prefixed with the name of the CTF.
+		name := strings.TrimPrefix(channel.Name(), archivedChannelPrefix(ctf))
+		files = append(files, exportFile{name: stripSolvedPrefix(name) + ".md", data: buf.Bytes()})
+	}
+	return files, nil
+}
+
+// writeExport writes a zip archive of files to w.
+func writeExport(w io.Writer, files []exportFile) error {
+	z := zip.NewWriter(w)
+	for _, file := range files {
+		f, err := z.Create(file.name)
+		if err != nil {
+			return err
+		}
+
+		if _, err := f.Write(file.data); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// splitExport zips files into archives no bigger than limit. Files are kept
+// whole, so it fails if one of them doesn't fit on its own.
+func splitExport(files []exportFile, limit int) ([][]byte, error) {
+	parts := [][]byte{}
+	part, last := []exportFile{}, []byte(nil)
+	for _, file := range files {
+		// Try with the file in the current archive, then on its own.
+		for {
+			var buf bytes.Buffer
+			if err := writeExport(&buf, append(slices.Clone(part), file)); err != nil {
+				return nil, err
+			}
+
+			if buf.Len() <= limit {
+				part, last = append(part, file), buf.Bytes()
+				break
+			} else if len(part) == 0 {
+				return nil, ctfbot.Errorf(ctfbot.EINVALID, "`%s` is too big to be uploaded to Discord, even zipped. Export the CTF with `ctfbotd export` instead.", file.name)
+			}
+
+			parts, part = append(parts, last), []exportFile{}
+		}
+	}
+
+	if len(part) > 0 {
+		return append(parts, last), nil
+	}
+
+	// An empty export still gets its archive.
+	var buf bytes.Buffer
+	if err := writeExport(&buf, nil); err != nil {
+		return nil, err
+	}
+	return [][]byte{buf.Bytes()}, nil
+}
+
+// exporter renders channels to Markdown, caching the names of the users.
+type exporter struct {
+	server *Server
+	users  map[string]string
+}
+
+// exportChannel writes the challenge details, if any, and the messages of
+// channel to w.
+func (e *exporter) exportChannel(ctx context.Context, channel discord.GuildChannel, w io.Writer) error {
+	fmt.Fprintf(w, "# %s\n\n", stripSolvedPrefix(channel.Name()))
+
+	chal, err := e.server.ChallengeService.FindChallengeByChannelID(ctx, channel.ID().String())
+	if err == nil {
+		if err := e.exportChallenge(ctx, chal, w); err != nil {
+			return err
+		}
+	} else if ctfbot.ErrorCode(err) != ctfbot.ENOTFOUND {
+		return err
+	}
+
+	messages, err := e.server.channelMessages(channel.ID())
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "## Messages\n\n")
+	for _, msg := range messages {
+		fmt.Fprintf(w, "**%s**, %s\n\n", msg.Author.Username, msg.CreatedAt.UTC().Format(time.DateTime+" UTC"))
+
+		if msg.Content != "" {
+			fmt.Fprintf(w, "%s\n\n", msg.Content)
+		}
+
+		for _, embed := range msg.Embeds {
+			if embed.Title != "" {
+				fmt.Fprintf(w, "> **%s**\n", embed.Title)
+			}
+			if embed.Description != "" {
+				fmt.Fprintf(w, "> %s\n", strings.ReplaceAll(embed.Description, "\n", "\n> "))
+			}
+			fmt.Fprintln(w)
+		}
+
+		for _, attachment := range msg.Attachments {
+			fmt.Fprintf(w, "- Attachment: [%s](%s)\n", attachment.Filename, attachment.URL)
+		}
+		if len(msg.Attachments) > 0 {
+			fmt.Fprintln(w)
+		}
+	}
+	return nil
+}
+
+// exportChallenge writes the details and the solves of chal to w.
+func (e *exporter) exportChallenge(ctx context.Context, chal *ctfbot.Challenge, w io.Writer) error {
+	category := chal.Category
+	if category == "" {
+		category = "Unknown"
+	}
+
+	points := "N/A"
+	if chal.Points > 0 {
+		points = strconv.Itoa(chal.Points)
+	}
+
+	fmt.Fprintf(w, "- Challenge: %s\n- Category: %s\n- Points: %s\n", chal.Name, category, points)
+
+	solves, _, err := e.server.SolveService.FindSolves(ctx, ctfbot.SolveFilter{ChallengeID: &chal.ID})
+	if err != nil {
+		return err
+	}
+
+	for _, solve := range solves {
+		solvedBy := e.userName(solve.UserID)
+		if solve.Blood {
+			solvedBy += " (first blood)"
+		}
+
+		fmt.Fprintf(w, "- Solved by: %s, %s\n", solvedBy, solve.SolvedAt.UTC().Format(time.DateTime+" UTC"))
+		if solve.Flag != "" {
+			fmt.Fprintf(w, "- Flag: `%s`\n", solve.Flag)
+		}
+	}
+	if len(solves) == 0 {
+		fmt.Fprintf(w, "- Not solved\n")
+	}
+
+	fmt.Fprintln(w)
+	return nil
+}
+
+// userName returns the name of the user identified by id. It falls back to
+// the ID itself if the user can't be found.
+func (e *exporter) userName(id string) string {
+	if name, ok := e.users[id]; ok {
+		return name
+	}
+
+	name := id
+	if userID, err := snowflake.Parse(id); err == nil {
+		if user, err := e.server.client.Rest().GetUser(userID); err == nil {
+			name = user.Username
+		}
+	}
+
+	e.users[id] = name
+	return name
+}
+
+// channelMessages returns every message of the channel, oldest first.
+func (s *Server) channelMessages(channelID snowflake.ID) ([]discord.Message, error) {
+	messages := []discord.Message{}
+
+	// Walk back the history, one page at a time.
+	var before snowflake.ID
+	for {
+		page, err := s.client.Rest().GetMessages(channelID, 0, before, 0, maxMessagesPerRequest)
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, page...)
+		if len(page) < maxMessagesPerRequest {
+			break
+		}
+		before = page[len(page)-1].ID
+	}
+
+	slices.SortFunc(messages, func(a, b discord.Message) int {
+		return a.ID.Time().Compare(b.ID.Time())
+	})
+	return messages, nil
+}
+
+// exportFileName returns the name of the export of ctf.
+func exportFileName(ctf *ctfbot.CTF) string {
+	return strings.Join(strings.Fields(ctf.Name), "-") + ".zip"
+}
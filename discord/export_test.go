@@ -0,0 +1,87 @@
+package discord
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/havce/ctfbot"
+)
+
+func TestSplitExport(t *testing.T) {
+	// Random data doesn't compress, so the sizes are predictable.
+	r := rand.New(rand.NewChaCha8([32]byte{}))
+	newFiles := func(n, size int) []exportFile {
+		files := make([]exportFile, n)
+		for i := range files {
+			files[i] = exportFile{name: fmt.Sprintf("chal-%d.md", i), data: make([]byte, size)}
+			for j := range files[i].data {
+				files[i].data[j] = byte(r.Uint32())
+			}
+		}
+		return files
+	}
+
+	for _, tt := range []struct {
+		name  string
+		files []exportFile
+		parts int
+	}{
+		{"Empty", nil, 1},
+		{"Small", newFiles(3, 100), 1},
+		{"OnePerPart", newFiles(3, 600), 3},
+		{"SomePerPart", newFiles(10, 200), 4},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := splitExport(tt.files, 1000)
+			if err != nil {
+				t.Fatal(err)
+			} else if got, want := len(parts), tt.parts; got != want {
+				t.Fatalf("len=%d, want %d", got, want)
+			}
+
+			// Ensure every file ends up whole in one of the archives, in
+			// order.
+			var files []exportFile
+			for _, part := range parts {
+				if len(part) > 1000 {
+					t.Fatalf("len=%d, want at most 1000", len(part))
+				}
+
+				z, err := zip.NewReader(bytes.NewReader(part), int64(len(part)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, f := range z.File {
+					rc, err := f.Open()
+					if err != nil {
+						t.Fatal(err)
+					}
+					var buf bytes.Buffer
+					if _, err := buf.ReadFrom(rc); err != nil {
+						t.Fatal(err)
+					}
+					_ = rc.Close()
+					files = append(files, exportFile{name: f.Name, data: buf.Bytes()})
+				}
+			}
+
+			if got, want := len(files), len(tt.files); got != want {
+				t.Fatalf("files=%d, want %d", got, want)
+			}
+			for i := range files {
+				if files[i].name != tt.files[i].name || !bytes.Equal(files[i].data, tt.files[i].data) {
+					t.Fatalf("file %d=%q, want %q", i, files[i].name, tt.files[i].name)
+				}
+			}
+		})
+	}
+
+	t.Run("ErrTooBig", func(t *testing.T) {
+		if _, err := splitExport(newFiles(1, 2000), 1000); ctfbot.ErrorCode(err) != ctfbot.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
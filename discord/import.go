@@ -0,0 +1,80 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/disgoorg/disgo/handler"
+	"github.com/havce/ctfbot"
+)
+
+func (s *Server) handleImport(event *handler.CommandEvent) error {
+	data := event.SlashCommandInteractionData()
+
+	parentChannel, err := s.parentChannel(event.Channel().ID())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	ctf, err := s.ctfFromCategory(context.TODO(), parentChannel)
+	if err != nil {
+		return Error(event, err)
+	}
+
+	// Use the stored credentials, unless told otherwise.
+	var platform ctfbot.Platform
+	if platformURL, ok := data.OptString("url"); ok {
+		kind := ctfbot.PlatformCTFd
+		if v, ok := data.OptString("platform"); ok {
+			kind = v
+		}
+
+		platform, err = newPlatform(kind, platformURL, data.String("token"), data.String("session"))
+	} else {
+		platform, err = s.platformForCTF(context.TODO(), ctf)
+	}
+	if err != nil {
+		return Error(event, err)
+	}
+
+	imported, skipped, err := importChallenges(context.TODO(), platform, func(chal *ctfbot.Challenge) error {
+		return s.createChallenge(context.TODO(), *event.GuildID(), ctf, parentChannel.ID(), chal, event.User())
+	})
+	if err != nil {
+		return Error(event, err)
+	}
+
+	s.refreshBoard(context.TODO(), ctf)
+
+	Respond(event, "Import completed",
+		fmt.Sprintf("Imported %d challenges, skipped %d already existing.", imported, skipped))
+	return nil
+}
+
+// importChallenges creates the challenges found on platform with create,
+// skipping the ones that already exist.
+func importChallenges(ctx context.Context, platform ctfbot.Platform, create func(chal *ctfbot.Challenge) error) (imported, skipped int, err error) {
+	challenges, err := platform.FindChallenges(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, c := range challenges {
+		chal := &ctfbot.Challenge{
+			Name:     c.Name,
+			Category: strings.ToLower(c.Category),
+			Points:   c.Points,
+		}
+
+		err := create(chal)
+		if ctfbot.ErrorCode(err) == ctfbot.ECONFLICT {
+			skipped++
+			continue
+		} else if err != nil {
+			return imported, skipped, err
+		}
+		imported++
+	}
+	return imported, skipped, nil
+}
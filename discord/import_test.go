@@ -0,0 +1,71 @@
+package discord
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/havce/ctfbot"
+	"github.com/havce/ctfbot/ctfd"
+	"github.com/havce/ctfbot/ctfd/ctfdtest"
+	"github.com/havce/ctfbot/sqlite"
+)
+
+func TestImportChallenges(t *testing.T) {
+	ctx := context.Background()
+
+	db := MustOpenDB(t)
+
+	ctf := &ctfbot.CTF{Name: "havcectf", RoleID: "1", Start: time.Now(), Finish: time.Now().Add(48 * time.Hour)}
+	if err := sqlite.NewCTFService(db).CreateCTF(ctx, ctf); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stand in for the channels, which would be created on Discord.
+	chals, channels := sqlite.NewChallengeService(db), 0
+	create := func(chal *ctfbot.Challenge) error {
+		channels++
+		chal.CTFID, chal.ChannelID = ctf.ID, strconv.Itoa(channels)
+		return chals.CreateChallenge(ctx, chal)
+	}
+
+	if err := create(&ctfbot.Challenge{Name: "rsa", Category: "crypto"}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := ctfdtest.NewServer("token", "session")
+	defer s.Close()
+
+	s.Challenges = []ctfd.Challenge{
+		{ID: 1, Name: "baby pwn", Category: "Pwn", Value: 100},
+		{ID: 2, Name: "rsa", Category: "Crypto", Value: 500},
+		{ID: 3, Name: "web 2.0", Category: "Web", Value: 200},
+	}
+
+	platform := ctfd.NewClient(s.URL)
+	platform.Token = "token"
+
+	// Ensure the challenge already stored is skipped, and the import goes on.
+	imported, skipped, err := importChallenges(ctx, platform, create)
+	if err != nil {
+		t.Fatal(err)
+	} else if imported != 2 || skipped != 1 {
+		t.Fatalf("imported=%d skipped=%d, want 2 and 1", imported, skipped)
+	}
+
+	if got, _, err := chals.FindChallenges(ctx, ctfbot.ChallengeFilter{CTFID: &ctf.ID}); err != nil {
+		t.Fatal(err)
+	} else if len(got) != 3 {
+		t.Fatalf("len=%d, want 3", len(got))
+	} else if got[1].Name != "baby pwn" || got[1].Category != "pwn" || got[1].Points != 100 {
+		t.Fatalf("unexpected challenge: %+v", got[1])
+	}
+
+	// Ensure importing again doesn't create anything.
+	if imported, skipped, err := importChallenges(ctx, platform, create); err != nil {
+		t.Fatal(err)
+	} else if imported != 0 || skipped != 3 {
+		t.Fatalf("imported=%d skipped=%d, want 0 and 3", imported, skipped)
+	}
+}
@@ -2,6 +2,9 @@ package discord
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -14,153 +17,341 @@ import (
 const (
 	DefaultDisplayLimit = 9
 	DefaultWeeks        = 2
+
+	// Keeps the custom IDs of the pagination buttons short enough.
+	maxInfoWeeks = 52
 )
 
-func (s *Server) handleInfoCTF(vote bool) func(event *handler.CommandEvent) error {
-	return func(event *handler.CommandEvent) error {
-		weeks := DefaultWeeks
-		maybeWeeks, ok := event.SlashCommandInteractionData().OptInt("weeks")
-		if ok && maybeWeeks > 0 {
-			weeks = maybeWeeks
-		}
+// Keys of the /info query, kept short as the query goes in the custom IDs of
+// the pagination buttons. Those can't be longer than 100 characters.
+const (
+	infoKeyStart        = "t"
+	infoKeyWeeks        = "w"
+	infoKeyFormat       = "f"
+	infoKeyOnSite       = "o"
+	infoKeyWeight       = "mw"
+	infoKeyRestrictions = "r"
+)
 
-		now := time.Now()
-		finish := time.Now().Add(time.Duration(weeks) * 24 * 7 * time.Hour)
+func (s *Server) handleInfoCTF(event *handler.CommandEvent) error {
+	query, err := infoQuery(event.SlashCommandInteractionData(), time.Now())
+	if err != nil {
+		return Error(event, err)
+	}
 
-		events, err := s.CTFTimeClient.FindEvents(context.TODO(), ctftime.EventFilter{
-			Start:  &now,
-			Finish: &finish,
-			Limit:  DefaultDisplayLimit,
-		})
-		if err != nil {
-			return Error(event, err)
+	content, embeds, components, err := s.infoPage(query, 0)
+	if err != nil {
+		return Error(event, err)
+	}
+
+	_, err = event.CreateFollowupMessage(discord.NewMessageCreateBuilder().
+		SetContent(content).
+		SetEmbeds(embeds...).
+		SetContainerComponents(components...).
+		Build(),
+	)
+	if err != nil {
+		return Error(event, err)
+	}
+	return nil
+}
+
+// handleInfoPage replaces the events shown by /info with the ones of another
+// page.
+func (s *Server) handleInfoPage(event *handler.ComponentEvent) error {
+	update := discord.NewMessageUpdateBuilder()
+
+	content, embeds, components, err := s.infoPageFromVars(event.Vars)
+	if err != nil {
+		// The message is updated in place, so the error goes there too.
+		if ctfbot.ErrorCode(err) == ctfbot.EINTERNAL {
+			event.Client().Logger().Error("Internal server error", "err", err)
 		}
+		update.SetContent("").SetEmbeds(messageEmbedError(ctfbot.ErrorMessage(err)))
+	} else {
+		update.SetContent(content).SetEmbeds(embeds...).SetContainerComponents(components...)
+	}
 
-		embeds := []discord.Embed{}
+	_, err = event.UpdateInteractionResponse(update.Build())
+	return err
+}
 
-		for i, event := range events {
-			orga := ""
-			for _, team := range event.Organizers {
-				orga += team.Name + " "
-			}
-			orga = truncate(orga, 100)
-			if orga == "" {
-				orga = "Unknown"
-			}
+// infoPageFromVars returns the page of /info the custom ID of a pagination
+// button points to.
+func (s *Server) infoPageFromVars(vars map[string]string) (string, []discord.Embed, []discord.ContainerComponent, error) {
+	offset, err := strconv.Atoi(vars["offset"])
+	if err != nil || offset < 0 {
+		return "", nil, nil, ctfbot.Errorf(ctfbot.EINVALID, "Invalid page.")
+	}
 
-			title := event.Title
-			if title == "" {
-				title = "Untitled Event"
-			}
-			title = truncate(title, 100)
+	query, err := url.ParseQuery(vars["query"])
+	if err != nil {
+		return "", nil, nil, ctfbot.Errorf(ctfbot.EINVALID, "Invalid filters.")
+	}
+	return s.infoPage(query, offset)
+}
 
-			if vote {
-				title = ctfbot.Itoe(i+1) + " " + title
-			}
+// infoPage returns the embeds of the events matching query, starting from
+// offset, and the buttons to browse the other pages. The content tells when
+// some events couldn't be searched.
+func (s *Server) infoPage(query url.Values, offset int) (string, []discord.Embed, []discord.ContainerComponent, error) {
+	now := time.Now()
 
-			description := truncate(event.Description, 200)
+	filter, err := eventFilter(query)
+	if err != nil {
+		return "", nil, nil, err
+	}
 
-			var thumbnail *discord.EmbedResource
-			if isValidURL(event.Logo) {
-				thumbnail = &discord.EmbedResource{
-					URL:    event.Logo,
-					Width:  100,
-					Height: 100,
-				}
-			}
+	// Ask for one more event, to know whether there's a next page.
+	filter.Offset, filter.Limit = offset, DefaultDisplayLimit+1
+	events, n, err := s.CTFTimeClient.FindEvents(context.TODO(), filter)
+	if err != nil {
+		return "", nil, nil, err
+	}
 
-			ctfTimeURL := event.CTFTimeURL
-			if !isValidURL(ctfTimeURL) {
-				ctfTimeURL = "N/A"
-			}
+	// CTFTime doesn't give more than a fixed number of events at once, the
+	// other ones are never shown nor filtered.
+	content := ""
+	if n >= ctftime.MaxEventsLimit {
+		content = fmt.Sprintf(":warning: Only the first %d CTFs of the period were searched, ask for fewer weeks to see the others.",
+			ctftime.MaxEventsLimit)
+	}
+
+	if len(events) == 0 {
+		return "", nil, nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "No upcoming CTFs match your filters.")
+	}
+
+	more := len(events) > DefaultDisplayLimit
+	if more {
+		events = events[:DefaultDisplayLimit]
+	}
+
+	embeds := []discord.Embed{}
+
+	for _, event := range events {
+		orga := ""
+		for _, team := range event.Organizers {
+			orga += team.Name + " "
+		}
+		orga = truncate(orga, 100)
+		if orga == "" {
+			orga = "Unknown"
+		}
+
+		title := event.Title
+		if title == "" {
+			title = "Untitled Event"
+		}
+		title = truncate(title, 100)
 
-			ctfLink := event.URL
-			if !isValidURL(ctfLink) {
-				ctfLink = "N/A"
+		description := truncate(event.Description, 200)
+
+		var thumbnail *discord.EmbedResource
+		if isValidURL(event.Logo) {
+			thumbnail = &discord.EmbedResource{
+				URL:    event.Logo,
+				Width:  100,
+				Height: 100,
 			}
+		}
+
+		ctfTimeURL := event.CTFTimeURL
+		if !isValidURL(ctfTimeURL) {
+			ctfTimeURL = "N/A"
+		}
+
+		ctfLink := event.URL
+		if !isValidURL(ctfLink) {
+			ctfLink = "N/A"
+		}
 
-			embed := discord.Embed{
-				Title:       title,
-				Description: description,
-				Footer: &discord.EmbedFooter{
-					Text: "Informations provided here may be incorrect or out of date",
+		embed := discord.Embed{
+			Title:       title,
+			Description: description,
+			Footer: &discord.EmbedFooter{
+				Text: "Informations provided here may be incorrect or out of date",
+			},
+			Color:     ColorNotQuiteBlack,
+			Thumbnail: thumbnail,
+			Timestamp: &now,
+			Fields: []discord.EmbedField{
+				{
+					Name:  "Organizers",
+					Value: orga,
 				},
-				Color:     ColorNotQuiteBlack,
-				Thumbnail: thumbnail,
-				Timestamp: &now,
-				Fields: []discord.EmbedField{
-					{
-						Name:  "Organizers",
-						Value: orga,
-					},
-					{
-						Name:  "Starts",
-						Value: formatTime(&event.Start),
-					},
-					{
-						Name:  "Ends",
-						Value: formatTime(&event.Finish),
-					},
-					{
-						Name:  "Rating",
-						Value: strconv.FormatFloat(event.Weight, 'f', 2, 64),
-					},
-					{
-						Name:  "Enrolled participants",
-						Value: strconv.Itoa(event.Participants),
-					},
-					{
-						Name:  "CTFTime",
-						Value: ctfTimeURL,
-					},
-					{
-						Name:  "CTF link",
-						Value: ctfLink,
-					},
+				{
+					Name:  "Starts",
+					Value: formatTime(&event.Start),
 				},
-			}
+				{
+					Name:  "Ends",
+					Value: formatTime(&event.Finish),
+				},
+				{
+					Name:  "Format",
+					Value: eventFormat(event),
+				},
+				{
+					Name:  "Rating",
+					Value: strconv.FormatFloat(event.Weight, 'f', 2, 64),
+				},
+				{
+					Name:  "Enrolled participants",
+					Value: strconv.Itoa(event.Participants),
+				},
+				{
+					Name:  "CTFTime",
+					Value: ctfTimeURL,
+				},
+				{
+					Name:  "CTF link",
+					Value: ctfLink,
+				},
+			},
+		}
 
-			if isValidURL(event.URL) {
-				embed.URL = event.URL
-			}
+		if isValidURL(event.URL) {
+			embed.URL = event.URL
+		}
+
+		embeds = append(embeds, embed)
+	}
+
+	// The filters go along with the page in the custom IDs of the buttons.
+	encoded := query.Encode()
+	components := []discord.ContainerComponent{
+		discord.NewActionRow(
+			discord.NewSecondaryButton("Previous", fmt.Sprintf("/info/%d/%s", max(offset-DefaultDisplayLimit, 0), encoded)).
+				WithDisabled(offset == 0),
+			discord.NewSecondaryButton("Next", fmt.Sprintf("/info/%d/%s", offset+DefaultDisplayLimit, encoded)).
+				WithDisabled(!more),
+		),
+	}
+	return content, embeds, components, nil
+}
+
+// infoQuery encodes the options of /info, and the time it was run at, so
+// that the pagination buttons can run the same query again.
+func infoQuery(data discord.SlashCommandInteractionData, now time.Time) (url.Values, error) {
+	query := url.Values{}
+	query.Set(infoKeyStart, strconv.FormatInt(now.Unix(), 10))
+
+	weeks := DefaultWeeks
+	if v, ok := data.OptInt("weeks"); ok && v > 0 {
+		weeks = min(v, maxInfoWeeks)
+	}
+	query.Set(infoKeyWeeks, strconv.Itoa(weeks))
 
-			embeds = append(embeds, embed)
+	if v, ok := data.OptString("format"); ok {
+		query.Set(infoKeyFormat, v)
+	}
+
+	if v, ok := data.OptBool("onsite"); ok {
+		query.Set(infoKeyOnSite, strconv.FormatBool(v))
+	}
+
+	if v, ok := data.OptFloat("weight"); ok {
+		if v < 0 {
+			return nil, ctfbot.Errorf(ctfbot.EINVALID, "The minimum weight can't be negative.")
 		}
+		// CTFTime weights have two decimals at most.
+		query.Set(infoKeyWeight, strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64))
+	}
 
-		// Create a vote through a separate REST API call not to mess with
-		// our complex system of mirrors and levers to handle errors.
-		if vote {
-			msg, err := s.client.Rest().CreateMessage(event.Channel().ID(), discord.NewMessageCreateBuilder().
-				SetEmbeds(embeds...).
-				SetEphemeral(false).
-				Build(),
-			)
-			if err != nil {
-				return Error(event, err)
-			}
+	if v, ok := data.OptString("restrictions"); ok {
+		query.Set(infoKeyRestrictions, v)
+	}
 
-			for i := range embeds {
-				err = s.client.Rest().AddReaction(event.Channel().ID(), msg.ID, ctfbot.Itoe(i+1))
-				if err != nil {
-					return Error(event, err)
-				}
-			}
+	return query, nil
+}
 
-			_, err = event.CreateFollowupMessage(discord.NewMessageCreateBuilder().
-				SetContent("Happy voting! :smile:").Build())
-			if err != nil {
-				return Error(event, err)
-			}
-			return nil
+// eventFilter returns the filter of the events query asks for, starting from
+// the time /info was run at.
+func eventFilter(query url.Values) (ctftime.EventFilter, error) {
+	seconds, err := strconv.ParseInt(query.Get(infoKeyStart), 10, 64)
+	if err != nil {
+		return ctftime.EventFilter{}, ctfbot.Errorf(ctfbot.EINVALID, "Invalid start.")
+	}
+	start := time.Unix(seconds, 0)
+
+	weeks, err := strconv.Atoi(query.Get(infoKeyWeeks))
+	if err != nil || weeks <= 0 {
+		weeks = DefaultWeeks
+	}
+	finish := start.Add(time.Duration(weeks) * 24 * 7 * time.Hour)
+
+	filter := ctftime.EventFilter{
+		Start:  &start,
+		Finish: &finish,
+	}
+
+	if query.Has(infoKeyFormat) {
+		format := query.Get(infoKeyFormat)
+		filter.Format = &format
+	}
+
+	if query.Has(infoKeyOnSite) {
+		onsite, err := strconv.ParseBool(query.Get(infoKeyOnSite))
+		if err != nil {
+			return filter, ctfbot.Errorf(ctfbot.EINVALID, "Invalid onsite filter.")
 		}
+		filter.OnSite = &onsite
+	}
 
-		_, err = event.CreateFollowupMessage(discord.NewMessageCreateBuilder().
-			SetEmbeds(embeds...).
-			Build(),
-		)
+	if query.Has(infoKeyWeight) {
+		weight, err := strconv.ParseFloat(query.Get(infoKeyWeight), 64)
 		if err != nil {
-			return Error(event, err)
+			return filter, ctfbot.Errorf(ctfbot.EINVALID, "Invalid weight filter.")
+		}
+		filter.MinWeight = &weight
+	}
+
+	if query.Has(infoKeyRestrictions) {
+		restrictions := query.Get(infoKeyRestrictions)
+		filter.Restrictions = &restrictions
+	}
+
+	return filter, nil
+}
+
+// eventFormat describes the format of event, and where it's played.
+func eventFormat(event *ctftime.Event) string {
+	format := event.Format
+	if format == "" {
+		format = "Unknown"
+	}
+
+	if event.OnSite {
+		format += ", onsite"
+		if event.Location != "" {
+			format += " in " + event.Location
 		}
-		return nil
+	} else {
+		format += ", online"
+	}
+
+	if event.Restrictions != "" {
+		format += fmt.Sprintf(" (%s)", event.Restrictions)
+	}
+	return truncate(format, 1024)
+}
+
+// upcomingEvents returns at most limit CTFTime events starting within the
+// number of weeks asked for by the command.
+func (s *Server) upcomingEvents(data discord.SlashCommandInteractionData, limit int) ([]*ctftime.Event, error) {
+	weeks := DefaultWeeks
+	maybeWeeks, ok := data.OptInt("weeks")
+	if ok && maybeWeeks > 0 {
+		weeks = maybeWeeks
 	}
+
+	now := time.Now()
+	finish := now.Add(time.Duration(weeks) * 24 * 7 * time.Hour)
+
+	events, _, err := s.CTFTimeClient.FindEvents(context.TODO(), ctftime.EventFilter{
+		Start:  &now,
+		Finish: &finish,
+		Limit:  limit,
+	})
+	return events, err
 }
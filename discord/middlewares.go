@@ -53,7 +53,7 @@ func (s *Server) MustBeInsideCTF(next handler.Handler) handler.Handler {
 			return err
 		}
 
-		_, err = s.CTFService.FindCTFByName(context.TODO(), parent.Name())
+		_, err = s.ctfFromCategory(context.TODO(), parent)
 		if err != nil {
 			_ = e.Respond(discord.InteractionResponseTypeCreateMessage,
 				discord.NewMessageCreateBuilder().
@@ -0,0 +1,33 @@
+package discord
+
+import (
+	"github.com/havce/ctfbot"
+	"github.com/havce/ctfbot/ctfd"
+	"github.com/havce/ctfbot/rctf"
+)
+
+// newPlatform returns a client for the scoring platform kind running at
+// platformURL. For CTFd, token is an access token and session a session
+// cookie. For rCTF, token is the team token.
+func newPlatform(kind, platformURL, token, session string) (ctfbot.Platform, error) {
+	if !isValidURL(platformURL) {
+		return nil, ctfbot.Errorf(ctfbot.EINVALID, "`%s` is not a valid URL.", platformURL)
+	}
+
+	switch kind {
+	case ctfbot.PlatformCTFd:
+		client := ctfd.NewClient(platformURL)
+		client.Token = token
+		client.Session = session
+		return client, nil
+	case ctfbot.PlatformRCTF:
+		if token == "" {
+			return nil, ctfbot.Errorf(ctfbot.EINVALID, "rCTF requires the team token.")
+		}
+		client := rctf.NewClient(platformURL)
+		client.TeamToken = token
+		return client, nil
+	default:
+		return nil, ctfbot.Errorf(ctfbot.EINVALID, "Unsupported platform `%s`.", kind)
+	}
+}
@@ -0,0 +1,576 @@
+package discord
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/havce/ctfbot"
+	"github.com/havce/ctfbot/ctftime"
+)
+
+const (
+	// How long polls are open, unless told otherwise.
+	DefaultPollDuration = 24 * time.Hour
+
+	// How many CTFs are up for a vote, unless told otherwise.
+	DefaultPollCandidates = 15
+
+	// Select menus can't have more than 25 options.
+	maxPollCandidates = 25
+
+	// How many CTFs can be ranked in ranked polls.
+	maxPollRanks = 3
+
+	// How often the polls past their deadline are checked.
+	pollInterval = time.Minute
+)
+
+func (s *Server) handleCommandVote(event *handler.CommandEvent) error {
+	data := event.SlashCommandInteractionData()
+
+	mode := ctfbot.PollSingle
+	if v, ok := data.OptString("mode"); ok {
+		mode = v
+	}
+
+	candidates := DefaultPollCandidates
+	if v, ok := data.OptInt("candidates"); ok && v > 0 {
+		candidates = min(v, maxPollCandidates)
+	}
+
+	deadline := time.Now().Add(DefaultPollDuration)
+	if v, ok := data.OptString("duration"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return Error(event, ctfbot.Errorf(ctfbot.EINVALID,
+				"`%s` is not a valid duration. Try something like `24h`.", v))
+		}
+		deadline = time.Now().Add(d)
+	}
+
+	events, err := s.upcomingEvents(data, candidates)
+	if err != nil {
+		return Error(event, err)
+	} else if len(events) == 0 {
+		return Error(event, ctfbot.Errorf(ctfbot.ENOTFOUND, "There are no upcoming CTFs to vote on."))
+	}
+
+	poll := &ctfbot.Poll{
+		Mode:      mode,
+		ChannelID: event.Channel().ID().String(),
+		EventIDs:  make([]int, 0, len(events)),
+		Titles:    make([]string, 0, len(events)),
+		Deadline:  deadline,
+	}
+	poll.AutoCreate, _ = data.OptBool("create")
+	for _, event := range events {
+		title := event.Title
+		if title == "" {
+			title = fmt.Sprintf("Event #%d", event.ID)
+		}
+		poll.EventIDs = append(poll.EventIDs, event.ID)
+		poll.Titles = append(poll.Titles, title)
+	}
+
+	// Create the poll through a separate REST API call, it's a public message
+	// unlike the response to the command.
+	msg, err := s.client.Rest().CreateMessage(event.Channel().ID(), discord.NewMessageCreateBuilder().
+		SetEmbeds(pollCandidates(poll, events), pollResults(poll, nil)).
+		SetContainerComponents(pollComponents(poll)...).
+		Build(),
+	)
+	if err != nil {
+		return Error(event, err)
+	}
+
+	// Remember the poll, to take votes and tally them at the deadline.
+	poll.MessageID = msg.ID.String()
+	if err := s.PollService.CreatePoll(context.TODO(), poll); err != nil {
+		_ = s.client.Rest().DeleteMessage(msg.ChannelID, msg.ID)
+		return Error(event, err)
+	}
+
+	_, err = event.CreateFollowupMessage(discord.NewMessageCreateBuilder().
+		SetContentf("Happy voting! :smile: Votes are counted %s.", formatRelativeTime(&deadline)).Build())
+	if err != nil {
+		return Error(event, err)
+	}
+	return nil
+}
+
+// handlePollVote replaces the votes of the user with the candidates picked
+// in the select menu of single choice and approval polls.
+func (s *Server) handlePollVote(event *handler.ComponentEvent) error {
+	poll, err := s.PollService.FindPollByMessageID(context.TODO(), event.Message.ID.String())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	votes := []*ctfbot.Vote{}
+	for _, value := range event.StringSelectMenuInteractionData().Values {
+		choice, err := strconv.Atoi(value)
+		if err != nil {
+			return Error(event, ctfbot.Errorf(ctfbot.EINVALID, "Invalid choice."))
+		}
+		votes = append(votes, &ctfbot.Vote{Choice: choice})
+	}
+	return s.castVotes(event, poll, votes)
+}
+
+// handlePollRank sets the candidate the user ranks at the rank of the select
+// menu, in ranked polls. Picking nothing leaves the rank empty.
+func (s *Server) handlePollRank(event *handler.ComponentEvent) error {
+	rank, err := strconv.Atoi(event.Vars["rank"])
+	if err != nil || rank < 1 {
+		return Error(event, ctfbot.Errorf(ctfbot.EINVALID, "Invalid rank."))
+	}
+
+	poll, err := s.PollService.FindPollByMessageID(context.TODO(), event.Message.ID.String())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	picked := []*ctfbot.Vote{}
+	for _, value := range event.StringSelectMenuInteractionData().Values {
+		choice, err := strconv.Atoi(value)
+		if err != nil {
+			return Error(event, ctfbot.Errorf(ctfbot.EINVALID, "Invalid choice."))
+		}
+		picked = append(picked, &ctfbot.Vote{Choice: choice, Rank: rank})
+	}
+
+	userID := event.User().ID.String()
+	current, _, err := s.PollService.FindVotes(context.TODO(), ctfbot.VoteFilter{
+		PollID: &poll.ID,
+		UserID: &userID,
+	})
+	if err != nil {
+		return Error(event, err)
+	}
+
+	// Keep the rest of the ballot, unless the picked candidate was ranked
+	// elsewhere: it moves to the new rank.
+	votes := picked
+	for _, vote := range current {
+		if vote.Rank == rank || slices.ContainsFunc(picked, func(v *ctfbot.Vote) bool { return v.Choice == vote.Choice }) {
+			continue
+		}
+		votes = append(votes, &ctfbot.Vote{Choice: vote.Choice, Rank: vote.Rank})
+	}
+	return s.castVotes(event, poll, votes)
+}
+
+// handlePollWithdraw takes back every vote of the user.
+func (s *Server) handlePollWithdraw(event *handler.ComponentEvent) error {
+	poll, err := s.PollService.FindPollByMessageID(context.TODO(), event.Message.ID.String())
+	if err != nil {
+		return Error(event, err)
+	}
+	return s.castVotes(event, poll, nil)
+}
+
+// castVotes replaces the votes of the user, refreshes the results shown by
+// the poll, and tells the user what their ballot looks like.
+func (s *Server) castVotes(event *handler.ComponentEvent, poll *ctfbot.Poll, votes []*ctfbot.Vote) error {
+	err := s.PollService.CastVotes(context.TODO(), poll.ID, event.User().ID.String(), votes)
+	if err != nil {
+		return Error(event, err)
+	}
+
+	all, _, err := s.PollService.FindVotes(context.TODO(), ctfbot.VoteFilter{PollID: &poll.ID})
+	if err != nil {
+		return Error(event, err)
+	}
+
+	if err := s.updatePollMessage(poll, &event.Message, all); err != nil {
+		s.client.Logger().Warn("Couldn't update poll results", "poll_id", poll.ID, "err", err)
+	}
+
+	if len(votes) == 0 {
+		Respond(event, ":ballot_box: Vote withdrawn", "You're not voting for any CTF anymore.")
+		return nil
+	}
+
+	slices.SortFunc(votes, func(a, b *ctfbot.Vote) int { return a.Rank - b.Rank })
+	lines := make([]string, 0, len(votes))
+	for _, vote := range votes {
+		line := "- " + candidateTitle(poll, vote.Choice)
+		if poll.Mode == ctfbot.PollRanked {
+			line = fmt.Sprintf("%d. %s", vote.Rank, candidateTitle(poll, vote.Choice))
+		}
+		lines = append(lines, line)
+	}
+	Respond(event, ":ballot_box: Vote recorded", "You're voting for:\n"+strings.Join(lines, "\n"))
+	return nil
+}
+
+// updatePollMessage replaces the results shown by message, the poll message,
+// with the ones of votes. Closed polls also lose their select menus.
+func (s *Server) updatePollMessage(poll *ctfbot.Poll, message *discord.Message, votes []*ctfbot.Vote) error {
+	embeds := []discord.Embed{}
+	if len(message.Embeds) > 0 {
+		embeds = append(embeds, message.Embeds[0])
+	}
+	embeds = append(embeds, pollResults(poll, votes))
+
+	update := discord.NewMessageUpdateBuilder().SetEmbeds(embeds...)
+	if !poll.ClosedAt.IsZero() {
+		update.ClearContainerComponents()
+	}
+
+	_, err := s.client.Rest().UpdateMessage(message.ChannelID, message.ID, update.Build())
+	return err
+}
+
+// pollCandidates returns the embed listing the candidates of poll.
+func pollCandidates(poll *ctfbot.Poll, events []*ctftime.Event) discord.Embed {
+	lines := make([]string, 0, len(events))
+	for i, event := range events {
+		title := poll.Titles[i]
+		if isValidURL(event.CTFTimeURL) {
+			title = fmt.Sprintf("[%s](%s)", title, event.CTFTimeURL)
+		}
+		lines = append(lines, fmt.Sprintf("**%d.** %s, %s, rating %s", i+1, title,
+			formatTime(&event.Start), strconv.FormatFloat(event.Weight, 'f', 2, 64)))
+	}
+
+	var description string
+	switch poll.Mode {
+	case ctfbot.PollSingle:
+		description = "Pick the CTF you'd like to play."
+	case ctfbot.PollApproval:
+		description = "Pick every CTF you'd like to play."
+	case ctfbot.PollRanked:
+		description = fmt.Sprintf("Rank up to %d CTFs you'd like to play, the least popular ones are knocked out until one has a majority.",
+			min(maxPollRanks, len(poll.EventIDs)))
+	}
+
+	return discord.NewEmbedBuilder().
+		SetTitle(":ballot_box: Which CTF should we play?").
+		SetColor(ColorNotQuiteBlack).
+		SetDescription(truncate(description+"\n\n"+strings.Join(lines, "\n"), maxEmbedDescriptionLength)).
+		SetFooter("Informations provided here may be incorrect or out of date", "").
+		Build()
+}
+
+// pollComponents returns the select menus used to vote on poll, and the
+// button to withdraw the votes.
+func pollComponents(poll *ctfbot.Poll) []discord.ContainerComponent {
+	options := make([]discord.StringSelectMenuOption, 0, len(poll.Titles))
+	for i, title := range poll.Titles {
+		options = append(options, discord.NewStringSelectMenuOption(truncate(fmt.Sprintf("%d. %s", i+1, title), 100), strconv.Itoa(i)))
+	}
+
+	none := 0
+	rows := []discord.ContainerComponent{}
+	switch poll.Mode {
+	case ctfbot.PollSingle:
+		rows = append(rows, discord.NewActionRow(
+			discord.NewStringSelectMenu("/poll/vote", "Vote for a CTF", options...),
+		))
+	case ctfbot.PollApproval:
+		menu := discord.NewStringSelectMenu("/poll/vote", "Vote for any number of CTFs", options...)
+		menu.MinValues, menu.MaxValues = &none, len(options)
+		rows = append(rows, discord.NewActionRow(menu))
+	case ctfbot.PollRanked:
+		for rank := 1; rank <= min(maxPollRanks, len(options)); rank++ {
+			menu := discord.NewStringSelectMenu(fmt.Sprintf("/poll/rank/%d", rank), fmt.Sprintf("Choice #%d", rank), options...)
+			menu.MinValues, menu.MaxValues = &none, 1
+			rows = append(rows, discord.NewActionRow(menu))
+		}
+	}
+
+	return append(rows, discord.NewActionRow(
+		discord.NewSecondaryButton("Withdraw vote", "/poll/withdraw"),
+	))
+}
+
+// pollResults returns the embed with the results of poll so far, or the final
+// ones once it's closed.
+func pollResults(poll *ctfbot.Poll, votes []*ctfbot.Vote) discord.Embed {
+	tally, winner := tallyPoll(poll, votes)
+
+	unit := "votes"
+	if poll.Mode == ctfbot.PollRanked {
+		unit = "first choices"
+	}
+
+	lines := make([]string, 0, len(tally)+1)
+	for i, n := range tally {
+		lines = append(lines, fmt.Sprintf("**%d.** %s: **%d** %s", i+1, candidateTitle(poll, i), n, unit))
+	}
+
+	voters := map[string]bool{}
+	for _, vote := range votes {
+		voters[vote.UserID] = true
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetFooterTextf("%d voters", len(voters))
+
+	if poll.ClosedAt.IsZero() {
+		lines = append(lines, fmt.Sprintf("\nVotes are counted %s.", formatRelativeTime(&poll.Deadline)))
+		return embed.
+			SetTitle(":bar_chart: Live results").
+			SetColor(ColorNotQuiteBlack).
+			SetDescription(truncate(strings.Join(lines, "\n"), maxEmbedDescriptionLength)).
+			Build()
+	}
+
+	switch {
+	case len(voters) == 0:
+		lines = append(lines, "\nNobody voted. :cricket:")
+	case winner == -1:
+		lines = append(lines, "\nIt's a tie! An admin has to break it.")
+	default:
+		lines = append(lines, fmt.Sprintf("\n:trophy: **%s** wins!", candidateTitle(poll, winner)))
+	}
+	return embed.
+		SetTitle(":bar_chart: Final results").
+		SetColor(ColorGreen).
+		SetDescription(truncate(strings.Join(lines, "\n"), maxEmbedDescriptionLength)).
+		Build()
+}
+
+// closePolls tallies the votes of the polls past their deadline.
+func (s *Server) closePolls(ctx context.Context) {
+	polls, err := s.PollService.FindDuePolls(ctx)
+	if err != nil {
+		s.client.Logger().Error("Couldn't fetch due polls", "err", err)
+		return
+	}
+
+	for _, poll := range polls {
+		if err := s.closePoll(ctx, poll); err != nil {
+			s.client.Logger().Warn("Couldn't close poll", "poll_id", poll.ID, "err", err)
+		}
+	}
+}
+
+// closePoll tallies the votes of poll, shows the final results in the poll
+// and announces the winner in reply to it. The CTF of the winner is created
+// if the poll asks for it. The poll is closed beforehand, so that it is never
+// tallied twice.
+func (s *Server) closePoll(ctx context.Context, poll *ctfbot.Poll) error {
+	poll, err := s.PollService.ClosePoll(ctx, poll.ID)
+	if err != nil {
+		return err
+	}
+
+	votes, _, err := s.PollService.FindVotes(ctx, ctfbot.VoteFilter{PollID: &poll.ID})
+	if err != nil {
+		return err
+	}
+
+	// Polls created before the titles were stored only have CTFTime IDs.
+	if len(poll.Titles) == 0 {
+		poll.Titles = s.candidateTitles(ctx, poll)
+	}
+
+	channelID, err := snowflake.Parse(poll.ChannelID)
+	if err != nil {
+		return err
+	}
+
+	messageID, err := snowflake.Parse(poll.MessageID)
+	if err != nil {
+		return err
+	}
+
+	results := pollResults(poll, votes)
+
+	// The results in the poll are only kept up to date in polls with select
+	// menus, the older ones are voted on with reactions.
+	if message, err := s.client.Rest().GetMessage(channelID, messageID); err != nil {
+		s.client.Logger().Warn("Couldn't fetch poll message", "poll_id", poll.ID, "err", err)
+	} else if len(message.Components) > 0 {
+		if err := s.updatePollMessage(poll, message, votes); err != nil {
+			s.client.Logger().Warn("Couldn't update poll results", "poll_id", poll.ID, "err", err)
+		}
+	}
+
+	_, winner := tallyPoll(poll, votes)
+
+	// Create the CTF right away if we were asked to.
+	if winner != -1 && poll.AutoCreate {
+		guildID, err := snowflake.Parse(s.GuildID)
+		if err != nil {
+			return err
+		}
+
+		if ctf, err := s.newCTF(ctx, guildID, poll.Titles[winner], poll.EventIDs[winner]); err != nil {
+			s.client.Logger().Warn("Couldn't create the winning CTF", "poll_id", poll.ID, "err", err)
+			results.Description += fmt.Sprintf("\nCouldn't create the CTF: %s", ctfbot.ErrorMessage(err))
+		} else {
+			results.Description += fmt.Sprintf("\n`%s` has been created, head to its registration channel to join.", ctf.Name)
+		}
+	}
+
+	results.Title = ":ballot_box: The votes are in!"
+	results.Description = truncate(results.Description, maxEmbedDescriptionLength)
+
+	_, err = s.client.Rest().CreateMessage(channelID, discord.NewMessageCreateBuilder().
+		SetMessageReferenceByID(messageID).
+		SetEmbeds(results).
+		Build())
+	return err
+}
+
+// candidateTitles returns the titles of the candidates of poll. Events that
+// can't be fetched from CTFTime are shown by ID.
+func (s *Server) candidateTitles(ctx context.Context, poll *ctfbot.Poll) []string {
+	titles := make([]string, len(poll.EventIDs))
+	for i, id := range poll.EventIDs {
+		titles[i] = fmt.Sprintf("Event #%d", id)
+
+		event, err := s.CTFTimeClient.FindEventByID(ctx, id)
+		if err != nil {
+			s.client.Logger().Warn("Couldn't fetch ctftime information", "event_id", id, "err", err)
+			continue
+		} else if event.Title != "" {
+			titles[i] = event.Title
+		}
+	}
+	return titles
+}
+
+// candidateTitle returns the title of the i-th candidate of poll.
+func candidateTitle(poll *ctfbot.Poll, i int) string {
+	if i < len(poll.Titles) {
+		return poll.Titles[i]
+	}
+	return fmt.Sprintf("Event #%d", poll.EventIDs[i])
+}
+
+// tallyPoll counts the votes of poll for each candidate, and returns the
+// index of the winner. In ranked polls only first choices are counted, and
+// the winner is found by instant runoff. The winner is -1 if nobody voted,
+// or if there's a tie.
+func tallyPoll(poll *ctfbot.Poll, votes []*ctfbot.Vote) ([]int, int) {
+	if poll.Mode != ctfbot.PollRanked {
+		tally := make([]int, len(poll.EventIDs))
+		for _, vote := range votes {
+			if vote.Choice < len(tally) {
+				tally[vote.Choice]++
+			}
+		}
+		return tally, pollWinner(tally)
+	}
+
+	// Ballots of the voters, best choice first.
+	ballots := map[string][]*ctfbot.Vote{}
+	for _, vote := range votes {
+		if vote.Choice < len(poll.EventIDs) {
+			ballots[vote.UserID] = append(ballots[vote.UserID], vote)
+		}
+	}
+
+	choices := make([][]int, 0, len(ballots))
+	for _, ballot := range ballots {
+		slices.SortFunc(ballot, func(a, b *ctfbot.Vote) int { return a.Rank - b.Rank })
+
+		ranked := make([]int, 0, len(ballot))
+		for _, vote := range ballot {
+			ranked = append(ranked, vote.Choice)
+		}
+		choices = append(choices, ranked)
+	}
+
+	tally := make([]int, len(poll.EventIDs))
+	for _, ranked := range choices {
+		tally[ranked[0]]++
+	}
+	return tally, instantRunoff(len(poll.EventIDs), choices)
+}
+
+// instantRunoff returns the candidate preferred by a majority of ballots,
+// after knocking out the least popular candidate one round at a time. Each
+// ballot lists candidates best first. Ties for the least popular candidate
+// are broken by how many ballots rank them first, then second and so on, and
+// then by the order of the candidates. Returns -1 if there are no ballots, or
+// if the last candidates standing can't be told apart.
+func instantRunoff(candidates int, ballots [][]int) int {
+	// How many ballots rank each candidate at each position.
+	ranks := make([][]int, candidates)
+	for i := range ranks {
+		ranks[i] = make([]int, candidates)
+	}
+	for _, ballot := range ballots {
+		for rank, choice := range ballot {
+			if rank < candidates {
+				ranks[choice][rank]++
+			}
+		}
+	}
+
+	out := make([]bool, candidates)
+	for {
+		// Exhausted ballots don't count towards the majority anymore.
+		tally, total := make([]int, candidates), 0
+		for _, ballot := range ballots {
+			for _, choice := range ballot {
+				if !out[choice] {
+					tally[choice]++
+					total++
+					break
+				}
+			}
+		}
+
+		if total == 0 {
+			return -1
+		}
+
+		standing := []int{}
+		for i, n := range tally {
+			if out[i] {
+				continue
+			} else if 2*n > total {
+				return i
+			}
+			standing = append(standing, i)
+		}
+
+		compare := func(a, b int) int {
+			if c := cmp.Compare(tally[a], tally[b]); c != 0 {
+				return c
+			}
+			return slices.Compare(ranks[a], ranks[b])
+		}
+
+		// Knock out the least popular candidate, the first listed if the
+		// ballots can't tell them apart. If that's all of them, they're tied.
+		fewest := slices.MinFunc(standing, compare)
+		if !slices.ContainsFunc(standing, func(i int) bool { return compare(i, fewest) != 0 }) {
+			return -1
+		}
+		out[fewest] = true
+	}
+}
+
+// pollWinner returns the index of the candidate with the most votes. Returns
+// -1 if nobody voted, or if there's a tie.
+func pollWinner(tally []int) int {
+	winner, best, tie := -1, 0, false
+	for i, n := range tally {
+		switch {
+		case n > best:
+			winner, best, tie = i, n, false
+		case n == best && n > 0:
+			tie = true
+		}
+	}
+
+	if tie {
+		return -1
+	}
+	return winner
+}
@@ -0,0 +1,88 @@
+package discord
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/havce/ctfbot"
+)
+
+func TestInstantRunoff(t *testing.T) {
+	const a, b, c = 0, 1, 2
+
+	for _, tt := range []struct {
+		name    string
+		ballots [][]int
+		winner  int
+	}{
+		{"NoBallots", nil, -1},
+		{"Majority", [][]int{{a}, {a}, {b}}, a},
+
+		// Half of the ballots isn't a majority.
+		{"Tie", [][]int{{a}, {a}, {b}, {b}}, -1},
+
+		// Only C is knocked out, its ballots go to B.
+		{"Transfer", [][]int{{a}, {a}, {a}, {b}, {b}, {c, b}, {c, b}}, b},
+
+		// B and C can't be told apart, they're knocked out one at a time.
+		{"LeastPopularTie", [][]int{{a}, {a}, {a}, {b}, {c}}, a},
+
+		// C is knocked out and one of its ballots is exhausted. A and B are
+		// tied on the rest, but more ballots rank A first.
+		{"Exhausted", [][]int{{a}, {a}, {a}, {b}, {b}, {c}, {c, b}}, a},
+
+		// Every candidate is ranked the same.
+		{"Cycle", [][]int{{a, b, c}, {b, c, a}, {c, a, b}}, -1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := instantRunoff(3, tt.ballots); got != tt.winner {
+				t.Fatalf("winner=%d, want %d", got, tt.winner)
+			}
+		})
+	}
+}
+
+func TestTallyPoll(t *testing.T) {
+	// votes builds the votes of a user, best first in ranked polls.
+	votes := func(userID int, choices ...int) []*ctfbot.Vote {
+		votes := []*ctfbot.Vote{}
+		for i, choice := range choices {
+			votes = append(votes, &ctfbot.Vote{UserID: strconv.Itoa(userID), Choice: choice, Rank: i + 1})
+		}
+		return votes
+	}
+
+	for _, tt := range []struct {
+		name   string
+		mode   string
+		votes  [][]*ctfbot.Vote
+		tally  []int
+		winner int
+	}{
+		{"Single", ctfbot.PollSingle, [][]*ctfbot.Vote{votes(1, 0), votes(2, 1), votes(3, 1)}, []int{1, 2, 0}, 1},
+		{"SingleTie", ctfbot.PollSingle, [][]*ctfbot.Vote{votes(1, 0), votes(2, 1)}, []int{1, 1, 0}, -1},
+		{"Approval", ctfbot.PollApproval, [][]*ctfbot.Vote{votes(1, 0, 2), votes(2, 2)}, []int{1, 0, 2}, 2},
+
+		// Votes for candidates the poll doesn't have are ignored.
+		{"UnknownChoice", ctfbot.PollSingle, [][]*ctfbot.Vote{votes(1, 0), votes(2, 5)}, []int{1, 0, 0}, 0},
+
+		// The tally shows first preferences, the winner comes from the
+		// runoff.
+		{"Ranked", ctfbot.PollRanked, [][]*ctfbot.Vote{
+			votes(1, 0), votes(2, 0), votes(3, 0),
+			votes(4, 1), votes(5, 1),
+			votes(6, 2, 1), votes(7, 2, 1),
+		}, []int{3, 2, 2}, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			poll := &ctfbot.Poll{Mode: tt.mode, EventIDs: []int{100, 200, 300}}
+			tally, winner := tallyPoll(poll, slices.Concat(tt.votes...))
+			if !slices.Equal(tally, tt.tally) {
+				t.Fatalf("tally=%v, want %v", tally, tt.tally)
+			} else if winner != tt.winner {
+				t.Fatalf("winner=%d, want %d", winner, tt.winner)
+			}
+		})
+	}
+}
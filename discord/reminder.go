@@ -0,0 +1,108 @@
+package discord
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/havce/ctfbot"
+)
+
+// How often the due reminders are checked.
+const reminderInterval = time.Minute
+
+// scheduleReminders stores the reminders of ctf: one for each of
+// ReminderOffsets before it starts, and one when it ends. Reminders that
+// would already be due are skipped. CTFs created by hand start when they're
+// created, so there's nothing to remind of before their start.
+func (s *Server) scheduleReminders(ctx context.Context, ctf *ctfbot.CTF) error {
+	now := time.Now()
+
+	reminders := make([]*ctfbot.Reminder, 0, len(s.ReminderOffsets)+1)
+	if ctf.CTFTimeID != 0 {
+		for _, offset := range s.ReminderOffsets {
+			reminders = append(reminders, &ctfbot.Reminder{
+				CTFID: ctf.ID,
+				Kind:  ctfbot.ReminderStart,
+				At:    ctf.Start.Add(-offset),
+			})
+		}
+	}
+
+	if !ctf.Finish.IsZero() {
+		reminders = append(reminders, &ctfbot.Reminder{
+			CTFID: ctf.ID,
+			Kind:  ctfbot.ReminderEnd,
+			At:    ctf.Finish,
+		})
+	}
+
+	reminders = slices.DeleteFunc(reminders, func(reminder *ctfbot.Reminder) bool {
+		return !reminder.At.After(now)
+	})
+
+	for _, reminder := range reminders {
+		err := s.ReminderService.CreateReminder(ctx, reminder)
+		if err != nil && ctfbot.ErrorCode(err) != ctfbot.ECONFLICT {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendReminders pings the players of the CTFs with a due reminder.
+func (s *Server) sendReminders(ctx context.Context) {
+	reminders, err := s.ReminderService.FindDueReminders(ctx)
+	if err != nil {
+		s.client.Logger().Error("Couldn't fetch due reminders", "err", err)
+		return
+	}
+
+	for _, reminder := range reminders {
+		if err := s.sendReminder(ctx, reminder); err != nil {
+			s.client.Logger().Warn("Couldn't send reminder", "reminder_id", reminder.ID, "err", err)
+		}
+	}
+}
+
+// sendReminder pings the players of the CTF in its general channel. The
+// reminder is marked as sent beforehand, so that it is never sent twice.
+func (s *Server) sendReminder(ctx context.Context, reminder *ctfbot.Reminder) error {
+	if _, err := s.ReminderService.MarkReminderSent(ctx, reminder.ID); err != nil {
+		return err
+	}
+
+	ctf, err := s.findCTFByID(ctx, reminder.CTFID)
+	if err != nil {
+		return err
+	}
+
+	general, err := s.generalChannel(ctf)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Rest().CreateMessage(general.ID(), discord.NewMessageCreateBuilder().
+		SetContentf("<@&%s>", ctf.RoleID).
+		SetEmbeds(messageEmbedReminder(ctf, reminder)).
+		Build())
+	return err
+}
+
+// messageEmbedReminder builds the embed of a reminder.
+func messageEmbedReminder(ctf *ctfbot.CTF, reminder *ctfbot.Reminder) discord.Embed {
+	if reminder.Kind == ctfbot.ReminderEnd {
+		return discord.NewEmbedBuilder().
+			SetTitle(":checkered_flag: Time's up!").
+			SetColor(ColorFuchsia).
+			SetDescriptionf("`%s` has ended. Thanks for playing!", ctf.Name).
+			Build()
+	}
+
+	return discord.NewEmbedBuilder().
+		SetTitle(":alarm_clock: Get ready!").
+		SetColor(ColorYellow).
+		SetDescriptionf("`%s` starts %s.", ctf.Name, formatRelativeTime(&ctf.Start)).
+		Build()
+}
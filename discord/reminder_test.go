@@ -0,0 +1,67 @@
+package discord
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/havce/ctfbot"
+	"github.com/havce/ctfbot/sqlite"
+)
+
+func TestServer_ScheduleReminders(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	for _, tt := range []struct {
+		name      string
+		ctftimeID int
+		start     time.Time
+		want      []string
+	}{
+		{"Upcoming", 1, now.Add(2 * time.Hour), []string{ctfbot.ReminderStart, ctfbot.ReminderStart, ctfbot.ReminderEnd}},
+		{"StartingSoon", 1, now.Add(30 * time.Minute), []string{ctfbot.ReminderStart, ctfbot.ReminderEnd}},
+		{"Started", 1, now.Add(-5 * time.Minute), []string{ctfbot.ReminderEnd}},
+
+		// CTFs created by hand start right away.
+		{"Manual", 0, now, []string{ctfbot.ReminderEnd}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := MustOpenDB(t)
+
+			ctf := &ctfbot.CTF{Name: "havcectf", RoleID: "1", CTFTimeID: tt.ctftimeID, Start: tt.start, Finish: tt.start.Add(48 * time.Hour)}
+			if err := sqlite.NewCTFService(db).CreateCTF(ctx, ctf); err != nil {
+				t.Fatal(err)
+			}
+
+			s := &Server{
+				ReminderService: sqlite.NewReminderService(db),
+				ReminderOffsets: []time.Duration{time.Hour, 10 * time.Minute},
+			}
+			if err := s.scheduleReminders(ctx, ctf); err != nil {
+				t.Fatal(err)
+			}
+
+			reminders, _, err := s.ReminderService.FindReminders(ctx, ctfbot.ReminderFilter{})
+			if err != nil {
+				t.Fatal(err)
+			} else if len(reminders) != len(tt.want) {
+				t.Fatalf("len=%d, want %d", len(reminders), len(tt.want))
+			}
+			for i, reminder := range reminders {
+				if reminder.Kind != tt.want[i] {
+					t.Fatalf("reminders[%d].Kind=%q, want %q", i, reminder.Kind, tt.want[i])
+				} else if !reminder.At.After(now) {
+					t.Fatalf("reminders[%d] is already due at %s", i, reminder.At)
+				}
+			}
+
+			// Ensure nothing is sent right after the CTF is created.
+			if due, err := s.ReminderService.FindDueReminders(ctx); err != nil {
+				t.Fatal(err)
+			} else if len(due) != 0 {
+				t.Fatalf("len=%d, want 0", len(due))
+			}
+		})
+	}
+}
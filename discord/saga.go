@@ -0,0 +1,46 @@
+package discord
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/disgoorg/disgo/rest"
+)
+
+// saga keeps track of the resources created along a multi-step operation,
+// so that they can be cleaned up if one of the steps fails.
+type saga struct {
+	logger        *slog.Logger
+	compensations []compensation
+}
+
+// compensation undoes a completed step of a saga.
+type compensation struct {
+	name string
+	fn   func() error
+}
+
+// undo registers fn to undo the step that has just completed.
+func (sg *saga) undo(name string, fn func() error) {
+	sg.compensations = append(sg.compensations, compensation{name: name, fn: fn})
+}
+
+// rollback undoes the completed steps, most recent first. Failures are only
+// logged, so that as much as possible is cleaned up.
+func (sg *saga) rollback() {
+	for i := len(sg.compensations) - 1; i >= 0; i-- {
+		c := sg.compensations[i]
+		if err := c.fn(); err != nil && !isNotFound(err) {
+			sg.logger.Warn("Couldn't roll back", "step", c.name, "err", err)
+		}
+	}
+	sg.compensations = nil
+}
+
+// isNotFound reports whether err is Discord telling us that the resource
+// doesn't exist, for instance because it was already deleted.
+func isNotFound(err error) bool {
+	var restErr rest.Error
+	return errors.As(err, &restErr) && restErr.Response != nil && restErr.Response.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,164 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/havce/ctfbot"
+)
+
+// How often the scheduled closings and archivals are checked.
+const scheduleInterval = time.Minute
+
+func (s *Server) handleSchedule(event *handler.CommandEvent) error {
+	data := event.SlashCommandInteractionData()
+
+	ctf, err := s.ctfFromChannel(event.Channel().ID())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	upd := ctfbot.CTFUpdate{}
+	if v, ok := data.OptString("close"); ok {
+		closeAt := time.Time{}
+		if v != "never" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return Error(event, ctfbot.Errorf(ctfbot.EINVALID,
+					"`%s` is not a valid duration. Try something like `2h`, or `never`.", v))
+			}
+			closeAt = ctf.Start.Add(d)
+		}
+		upd.CloseAt = &closeAt
+	}
+
+	if v, ok := data.OptInt("archive"); ok {
+		archiveAt := time.Time{}
+		if v > 0 {
+			if ctf.Finish.IsZero() {
+				return Error(event, ctfbot.Errorf(ctfbot.EINVALID,
+					"We don't know when `%s` ends, so it can't be archived automatically.", ctf.Name))
+			}
+			archiveAt = ctf.Finish.Add(time.Duration(v) * 24 * time.Hour)
+		}
+		upd.ArchiveAt = &archiveAt
+	}
+
+	// Just show the schedule if nothing has to change.
+	if upd.CloseAt != nil || upd.ArchiveAt != nil {
+		if ctf, err = s.CTFService.UpdateCTF(context.TODO(), ctf.Name, upd); err != nil {
+			return Error(event, err)
+		}
+	}
+
+	_, err = event.CreateFollowupMessage(discord.NewMessageCreateBuilder().
+		SetEmbeds(messageEmbedSchedule(ctf)).
+		Build())
+	return err
+}
+
+// messageEmbedSchedule builds the embed showing the schedule of ctf.
+func messageEmbedSchedule(ctf *ctfbot.CTF) discord.Embed {
+	closeAt := "Never"
+	if !ctf.CloseAt.IsZero() {
+		closeAt = formatTime(&ctf.CloseAt)
+	}
+
+	archiveAt := "Never"
+	if !ctf.ArchiveAt.IsZero() {
+		archiveAt = formatTime(&ctf.ArchiveAt)
+	}
+
+	return discord.NewEmbedBuilder().
+		SetTitle(fmt.Sprintf(":calendar: %s schedule", ctf.Name)).
+		SetColor(ColorBlurple).
+		AddField("Registrations close", closeAt, true).
+		AddField("Archived", archiveAt, true).
+		Build()
+}
+
+// runSchedules closes the registrations and archives the CTFs as scheduled.
+// Deletions that failed half-way are resumed too.
+func (s *Server) runSchedules(ctx context.Context) {
+	deleting := ctfbot.CTFStatusDeleting
+	ctfs, _, err := s.CTFService.FindCTFs(ctx, ctfbot.CTFFilter{Status: &deleting})
+	if err != nil {
+		s.client.Logger().Error("Couldn't fetch CTFs being deleted", "err", err)
+		return
+	}
+
+	for _, ctf := range ctfs {
+		if err := s.deleteCTF(ctx, ctf); err != nil {
+			s.client.Logger().Warn("Couldn't resume CTF deletion", "ctf", ctf.Name, "err", err)
+		}
+	}
+
+	// Only active CTFs are closed and archived, the service knows when.
+	ctfs, err = s.CTFService.FindCTFsToClose(ctx)
+	if err != nil {
+		s.client.Logger().Error("Couldn't fetch CTFs to close", "err", err)
+		return
+	}
+
+	for _, ctf := range ctfs {
+		if err := s.closeRegistrations(ctx, ctf); err != nil {
+			s.client.Logger().Warn("Couldn't close registrations", "ctf", ctf.Name, "err", err)
+		}
+	}
+
+	ctfs, err = s.CTFService.FindCTFsToArchive(ctx)
+	if err != nil {
+		s.client.Logger().Error("Couldn't fetch CTFs to archive", "err", err)
+		return
+	}
+
+	for _, ctf := range ctfs {
+		if err := s.scheduledArchive(ctx, ctf); err != nil {
+			s.client.Logger().Warn("Couldn't archive CTF", "ctf", ctf.Name, "err", err)
+		}
+	}
+}
+
+// closeRegistrations closes the registrations of ctf as scheduled, and lets
+// its players know. Nobody is told if an admin already closed them.
+func (s *Server) closeRegistrations(ctx context.Context, ctf *ctfbot.CTF) error {
+	// Clear the schedule, admins can still open the registrations again.
+	canJoin, closeAt := false, time.Time{}
+	_, err := s.CTFService.UpdateCTF(ctx, ctf.Name, ctfbot.CTFUpdate{
+		CanJoin: &canJoin,
+		CloseAt: &closeAt,
+	})
+	if err != nil {
+		return err
+	} else if !ctf.CanJoin {
+		return nil
+	}
+
+	general, err := s.generalChannel(ctf)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Rest().CreateMessage(general.ID(), discord.NewMessageCreateBuilder().
+		SetEmbeds(discord.NewEmbedBuilder().
+			SetTitle(":lock: Registrations closed").
+			SetColor(ColorYellow).
+			SetDescriptionf("Registrations for `%s` are now closed. Latecomers have to ask an admin.", ctf.Name).
+			Build()).
+		Build())
+	return err
+}
+
+// scheduledArchive archives ctf as scheduled.
+func (s *Server) scheduledArchive(ctx context.Context, ctf *ctfbot.CTF) error {
+	// Clear the schedule first, so that we don't try over and over.
+	archiveAt := time.Time{}
+	if _, err := s.CTFService.UpdateCTF(ctx, ctf.Name, ctfbot.CTFUpdate{ArchiveAt: &archiveAt}); err != nil {
+		return err
+	}
+
+	return s.archiveCTF(ctx, ctf, true)
+}
@@ -0,0 +1,175 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/havce/ctfbot"
+)
+
+// Default and maximum number of teams shown by /scoreboard.
+const (
+	defaultScoreboardTop = 10
+	maxScoreboardTop     = 50
+)
+
+func (s *Server) handleScoreboard(event *handler.CommandEvent) error {
+	top := defaultScoreboardTop
+	if v, ok := event.SlashCommandInteractionData().OptInt("top"); ok {
+		top = max(1, min(v, maxScoreboardTop))
+	}
+
+	ctf, err := s.ctfFromChannel(event.Channel().ID())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	platform, err := s.platformForCTF(context.TODO(), ctf)
+	if err != nil {
+		return Error(event, err)
+	}
+
+	scoreboard, err := platform.FindScoreboard(context.TODO(), top)
+	if err != nil {
+		return Error(event, err)
+	}
+
+	standing, err := platform.FindStanding(context.TODO())
+	if err != nil {
+		return Error(event, err)
+	}
+
+	lines := make([]string, 0, len(scoreboard)+2)
+	found := false
+	for _, team := range scoreboard {
+		line := standingLine(team)
+		if team.TeamName == standing.TeamName {
+			line, found = "**"+line+"**", true
+		}
+		lines = append(lines, line)
+	}
+
+	// Show where we are, even if we didn't make it to the top.
+	if !found && standing.Position > 0 {
+		lines = append(lines, "...", "**"+standingLine(standing)+"**")
+	}
+
+	if len(lines) == 0 {
+		lines = append(lines, "The scoreboard is empty.")
+	}
+
+	_, err = event.CreateFollowupMessage(discord.NewMessageCreateBuilder().
+		SetEmbeds(discord.NewEmbedBuilder().
+			SetTitle(":trophy: " + ctf.Name + " scoreboard").
+			SetColor(ColorBlurple).
+			SetDescription(truncate(strings.Join(lines, "\n"), maxBoardLength)).
+			Build()).
+		Build())
+	return err
+}
+
+// standingLine renders a single team of the scoreboard.
+func standingLine(standing *ctfbot.Standing) string {
+	return fmt.Sprintf("`#%d` %s: %d points", standing.Position, standing.TeamName, standing.Score)
+}
+
+// pollScoreboards takes a snapshot of our standing in every linked CTF.
+func (s *Server) pollScoreboards(ctx context.Context) {
+	credentials, _, err := s.CredentialsService.FindCredentials(ctx, ctfbot.CredentialsFilter{})
+	if err != nil {
+		s.client.Logger().Error("Couldn't fetch CTF credentials", "err", err)
+		return
+	}
+
+	for _, creds := range credentials {
+		if creds.Platform == "" {
+			continue
+		}
+
+		if err := s.pollScoreboard(ctx, creds.CTFID); err != nil {
+			s.client.Logger().Warn("Couldn't poll scoreboard", "ctf_id", creds.CTFID, "err", err)
+		}
+	}
+}
+
+// pollScoreboard stores our standing in a single CTF, if it changed since
+// the last snapshot. Rank changes are announced in the general channel.
+func (s *Server) pollScoreboard(ctx context.Context, ctfID int) error {
+	ctf, err := s.findCTFByID(ctx, ctfID)
+	if err != nil {
+		return err
+	} else if ctf.Status != ctfbot.CTFStatusActive {
+		return nil
+	}
+
+	platform, err := s.platformForCTF(ctx, ctf)
+	if err != nil {
+		return err
+	}
+
+	standing, err := platform.FindStanding(ctx)
+	if err != nil {
+		return err
+	} else if standing.Position == 0 {
+		// The scoreboard is hidden, or we haven't scored yet.
+		return nil
+	}
+
+	snapshots, _, err := s.SnapshotService.FindSnapshots(ctx, ctfbot.SnapshotFilter{CTFID: &ctf.ID, Limit: 1})
+	if err != nil {
+		return err
+	}
+
+	var last *ctfbot.Snapshot
+	if len(snapshots) > 0 {
+		last = snapshots[0]
+		if last.Position == standing.Position && last.Score == standing.Score {
+			return nil
+		}
+	}
+
+	err = s.SnapshotService.CreateSnapshot(ctx, &ctfbot.Snapshot{
+		CTFID:    ctf.ID,
+		Position: standing.Position,
+		Score:    standing.Score,
+	})
+	if err != nil {
+		return err
+	}
+
+	if last == nil || last.Position == standing.Position {
+		return nil
+	}
+
+	general, err := s.generalChannel(ctf)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Rest().CreateMessage(general.ID(), discord.NewMessageCreateBuilder().
+		SetEmbeds(messageEmbedRankChange(last, standing)).Build())
+	return err
+}
+
+// messageEmbedRankChange builds the embed announcing that we moved from the
+// position of last to the one of standing.
+func messageEmbedRankChange(last *ctfbot.Snapshot, standing *ctfbot.Standing) discord.Embed {
+	if standing.Position < last.Position {
+		return discord.NewEmbedBuilder().
+			SetTitle(":chart_with_upwards_trend: We're climbing!").
+			SetColor(ColorGreen).
+			SetDescriptionf("We're now `#%d` with %d points, up from `#%d`.",
+				standing.Position, standing.Score, last.Position).
+			Build()
+	}
+
+	return discord.NewEmbedBuilder().
+		SetTitle(":chart_with_downwards_trend: We've been overtaken").
+		SetColor(ColorYellow).
+		SetDescriptionf("We're now `#%d` with %d points, down from `#%d`.",
+			standing.Position, standing.Score, last.Position).
+		Build()
+}
@@ -2,6 +2,8 @@ package discord
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/disgoorg/disgo"
 	"github.com/disgoorg/disgo/bot"
@@ -23,26 +25,77 @@ type Server struct {
 	router handler.Router
 	client bot.Client
 
-	CTFService    ctfbot.CTFService
-	CTFTimeClient *ctftime.Client
+	CTFService          ctfbot.CTFService
+	ChallengeService    ctfbot.ChallengeService
+	SolveService        ctfbot.SolveService
+	ClaimService        ctfbot.ClaimService
+	CredentialsService  ctfbot.CredentialsService
+	SnapshotService     ctfbot.SnapshotService
+	ReminderService     ctfbot.ReminderService
+	PollService         ctfbot.PollService
+	AvailabilityService ctfbot.AvailabilityService
+	CTFTimeClient       ctftime.EventService
 
 	// Channel default names.
 	GeneralChannel      string
 	RegistrationChannel string
+
+	// Name of the categories archived CTFs are moved to.
+	ArchiveCategory string
+
+	// Whether to request the privileged message content intent. Without
+	// it, exports lack the content of most messages.
+	MessageContent bool
+
+	// How often the solves are fetched from the CTF platforms. Zero
+	// disables the sync.
+	SyncInterval time.Duration
+
+	// How often our position on the scoreboards is checked. Zero disables
+	// the tracking.
+	ScoreboardInterval time.Duration
+
+	// How often the CTFs in the database are checked against the roles and
+	// categories of the guild. Only the repairs limited to the database are
+	// made, the others are logged. Zero disables it.
+	ReconcileInterval time.Duration
+
+	// How long before the start of a CTF its players are reminded of it.
+	ReminderOffsets []time.Duration
+
+	// How long after the start of a CTF its registrations are closed, and
+	// how long after its end it is archived. Zero disables the schedule.
+	CloseAfter   time.Duration
+	ArchiveAfter time.Duration
+
+	// Channels whose topic must list their workers again, by the ID of
+	// their challenge.
+	topicMu sync.Mutex
+	topics  map[snowflake.ID]int
+
+	// Background workers, stopped on Close.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 func NewServer() *Server {
 	s := &Server{
 		router: handler.New(),
+		topics: make(map[snowflake.ID]int),
 	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
 
 	// Admin only routes. We repeat the Middleware injection because
 	// order of evaluation is important. It isn't super clean, but it works.
 	s.router.Group(func(r handler.Router) {
 		r.Use(AdminOnly)
 		r.Command("/new", s.handleCommandNewCTF)
-		r.Component("/new/{ctf}/create", s.handleCreateCTF)
-		r.Command("/vote", s.handleInfoCTF(true))
+		r.Component("/new/{ctf}/{event}/create", s.handleCreateCTF)
+		r.Command("/vote", s.handleCommandVote)
+		r.Command("/availability", s.handleAvailability)
+		r.Command("/unarchive", s.handleUnarchive)
+		r.Command("/doctor", s.handleDoctor)
 	})
 
 	// Admin only routes and must be under a registered CTF.
@@ -52,6 +105,11 @@ func NewServer() *Server {
 		r.Component("/delete/really", s.handleDeleteCTF)
 		r.Command("/close", s.handleUpdateCanJoin(false))
 		r.Command("/open", s.handleUpdateCanJoin(true))
+		r.Command("/blood", s.handleFlag(true))
+		r.Command("/import", s.handleImport)
+		r.Command("/creds/set", s.handleSetCredentials)
+		r.Command("/schedule", s.handleSchedule)
+		r.Command("/archive", s.handleArchive)
 	})
 
 	// These routes must be hit while inside of a CTF, but don't
@@ -61,8 +119,20 @@ func NewServer() *Server {
 
 		r.Component("/join/{ctf}", s.handleJoinCTF)
 		r.Command("/flag", s.handleFlag(false))
-		r.Command("/blood", s.handleFlag(true))
+		r.Command("/unflag", s.handleUnflag)
 		r.Command("/chal", s.handleNewChal)
+		r.Command("/working", s.handleWorking)
+		r.Command("/stop", s.handleStop)
+		r.Command("/who", s.handleWho)
+		r.Command("/creds/show", s.handleShowCredentials)
+		r.Command("/scoreboard", s.handleScoreboard)
+	})
+
+	// Exports also work in the archive, where channels don't belong to the
+	// category of a CTF anymore. The CTF is found by the handler itself.
+	s.router.Group(func(r handler.Router) {
+		r.Use(middleware.Defer(discord.InteractionTypeApplicationCommand, false, true))
+		r.Command("/export", s.handleExport)
 	})
 
 	// These routes can be used by anyone.
@@ -70,19 +140,38 @@ func NewServer() *Server {
 	s.router.Group(func(r handler.Router) {
 		r.Use(middleware.Defer(discord.InteractionTypeApplicationCommand, false, true))
 		r.Use(middleware.Defer(discord.InteractionTypeComponent, false, true))
-		r.Command("/info", s.handleInfoCTF(false))
+		r.Command("/info", s.handleInfoCTF)
+		r.Component("/poll/vote", s.handlePollVote)
+		r.Component("/poll/rank/{rank}", s.handlePollRank)
+		r.Component("/poll/withdraw", s.handlePollWithdraw)
+		r.Component("/availability/slot/{slot}", s.handleAvailabilitySlot)
+		r.Component("/availability/all", s.handleAvailabilityAll)
+		r.Component("/availability/none", s.handleAvailabilityNone)
+	})
+
+	// Pages of /info replace the message they're shown in.
+	s.router.Group(func(r handler.Router) {
+		r.Use(middleware.Defer(discord.InteractionTypeComponent, true, false))
+		r.Component("/info/{offset}/{query}", s.handleInfoPage)
 	})
 
 	return s
 }
 
 func (s *Server) Open(ctx context.Context) (err error) {
+	intents := []gateway.Intents{gateway.IntentGuilds}
+	if s.MessageContent {
+		intents = append(intents, gateway.IntentMessageContent)
+	}
+
 	s.client, err = disgo.New(
 		s.BotToken,
 		bot.WithGatewayConfigOpts(
-			gateway.WithIntents(gateway.IntentGuilds),
+			gateway.WithIntents(intents...),
+		),
+		bot.WithEventListeners(
+			s.router,
 		),
-		bot.WithEventListeners(s.router),
 		bot.WithCacheConfigOpts(
 			cache.WithCaches(cache.FlagChannels|cache.FlagMembers|cache.FlagRoles),
 		),
@@ -100,13 +189,65 @@ func (s *Server) Open(ctx context.Context) (err error) {
 		return err
 	}
 
-	return s.client.OpenGateway(ctx)
+	if err := s.client.OpenGateway(ctx); err != nil {
+		return err
+	}
+
+	if s.SyncInterval > 0 {
+		s.every(s.SyncInterval, s.syncSolves)
+	}
+
+	if s.ScoreboardInterval > 0 {
+		s.every(s.ScoreboardInterval, s.pollScoreboards)
+	}
+
+	if s.ReconcileInterval > 0 {
+		s.every(s.ReconcileInterval, s.reconcile)
+	}
+
+	s.every(reminderInterval, s.sendReminders)
+	s.every(scheduleInterval, s.runSchedules)
+	s.every(pollInterval, s.closePolls)
+	s.every(topicInterval, s.updateTopics)
+
+	return nil
+}
+
+// OpenREST creates a client that can only use the REST API, without
+// connecting to the gateway. Meant for the command line tools.
+func (s *Server) OpenREST() (err error) {
+	s.client, err = disgo.New(s.BotToken)
+	return err
 }
 
 func (s *Server) Close(ctx context.Context) error {
+	// Wait for the background workers to finish.
+	s.cancel()
+	s.wg.Wait()
+
 	if s.client != nil {
 		s.client.Close(ctx)
 	}
 
 	return nil
 }
+
+// every runs fn in the background each interval, until the server is closed.
+func (s *Server) every(interval time.Duration, fn func(ctx context.Context)) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				fn(s.ctx)
+			}
+		}
+	}()
+}
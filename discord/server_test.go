@@ -0,0 +1,21 @@
+package discord
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/havce/ctfbot/sqlite"
+)
+
+// MustOpenDB returns a new, open DB in a temporary directory, closed when
+// the test ends. Fatal on error.
+func MustOpenDB(tb testing.TB) *sqlite.DB {
+	tb.Helper()
+
+	db := sqlite.NewDB(filepath.Join(tb.TempDir(), "db"))
+	if err := db.Open(); err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { _ = db.Close() })
+	return db
+}
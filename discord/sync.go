@@ -0,0 +1,110 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/havce/ctfbot"
+)
+
+// syncSolves marks as solved the challenges our team has flagged on the
+// platform of every linked CTF, even if nobody used /flag.
+func (s *Server) syncSolves(ctx context.Context) {
+	credentials, _, err := s.CredentialsService.FindCredentials(ctx, ctfbot.CredentialsFilter{})
+	if err != nil {
+		s.client.Logger().Error("Couldn't fetch CTF credentials", "err", err)
+		return
+	}
+
+	for _, creds := range credentials {
+		if creds.Platform == "" {
+			continue
+		}
+
+		if err := s.syncCTFSolves(ctx, creds.CTFID); err != nil {
+			s.client.Logger().Warn("Couldn't sync solves", "ctf_id", creds.CTFID, "err", err)
+		}
+	}
+}
+
+// syncCTFSolves flags the challenges of a single CTF.
+func (s *Server) syncCTFSolves(ctx context.Context, ctfID int) error {
+	ctf, err := s.findCTFByID(ctx, ctfID)
+	if err != nil {
+		return err
+	} else if ctf.Status != ctfbot.CTFStatusActive {
+		return nil
+	}
+
+	platform, err := s.platformForCTF(ctx, ctf)
+	if err != nil {
+		return err
+	}
+
+	solves, err := platform.FindSolves(ctx)
+	if err != nil {
+		return err
+	}
+
+	chals, _, err := s.ChallengeService.FindChallenges(ctx, ctfbot.ChallengeFilter{CTFID: &ctf.ID})
+	if err != nil {
+		return err
+	}
+
+	for _, solve := range solves {
+		for _, chal := range chals {
+			if chal.Solved || !strings.EqualFold(chal.Name, solve.ChallengeName) {
+				continue
+			}
+
+			if err := s.syncChallengeSolve(ctx, ctf, chal, solve); err != nil {
+				s.client.Logger().Warn("Couldn't flag challenge", "ctf", ctf.Name, "challenge", chal.Name, "err", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// syncChallengeSolve flags chal after solve, and cheers for the solver just
+// like /flag does.
+func (s *Server) syncChallengeSolve(ctx context.Context, ctf *ctfbot.CTF, chal *ctfbot.Challenge, solve *ctfbot.PlatformSolve) error {
+	channelID, err := snowflake.Parse(chal.ChannelID)
+	if err != nil {
+		return err
+	}
+
+	channel, found := s.client.Caches().Channel(channelID)
+	if !found {
+		return ctfbot.Errorf(ctfbot.ENOTFOUND, "Channel of `%s` not found.", chal.Name)
+	}
+
+	// Somebody flagged it before we kept track of solves.
+	if stripSolvedPrefix(channel.Name()) != channel.Name() {
+		return nil
+	}
+
+	blood, err := s.isFirstBlood(ctx, ctf, chal)
+	if err != nil {
+		return err
+	}
+
+	// We don't know who flagged it on Discord, so we take the credit.
+	err = s.flagChallenge(ctx, ctf, &ctfbot.Solve{
+		ChallengeID: chal.ID,
+		UserID:      s.client.ID().String(),
+		Blood:       blood,
+		SolvedAt:    solve.SolvedAt,
+	}, channelID, channel.Name())
+	if err != nil {
+		return err
+	}
+
+	solver := "Somebody"
+	if solve.UserName != "" {
+		solver = fmt.Sprintf("`%s`", solve.UserName)
+	}
+	return s.announceFlag(channelID, channel.Name(), solver, blood)
+}
@@ -1,6 +1,7 @@
 package discord
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"time"
@@ -14,6 +15,14 @@ func formatTime(t *time.Time) string {
 	return fmt.Sprintf("<t:%d:F>", t.Unix())
 }
 
+func formatShortTime(t *time.Time) string {
+	return fmt.Sprintf("<t:%d:f>", t.Unix())
+}
+
+func formatRelativeTime(t *time.Time) string {
+	return fmt.Sprintf("<t:%d:R>", t.Unix())
+}
+
 func (s *Server) parentChannel(channelID snowflake.ID) (discord.GuildChannel, error) {
 	currentChannel, present := s.client.Caches().Channel(channelID)
 	if !present {
@@ -30,6 +39,121 @@ func (s *Server) parentChannel(channelID snowflake.ID) (discord.GuildChannel, er
 	return parentChannel, nil
 }
 
+// childChannels returns the channels inside the category parentID.
+func (s *Server) childChannels(parentID snowflake.ID) []discord.GuildChannel {
+	children := []discord.GuildChannel{}
+	s.client.Caches().ChannelsForEach(func(channel discord.GuildChannel) {
+		if channel.ParentID() == nil {
+			return
+		}
+
+		if *channel.ParentID() != parentID {
+			return
+		}
+
+		children = append(children, channel)
+	})
+	return children
+}
+
+// childChannelByName returns the channel named name inside the category parentID.
+func (s *Server) childChannelByName(parentID snowflake.ID, name string) (discord.GuildChannel, error) {
+	for _, channel := range s.childChannels(parentID) {
+		if channel.Name() == name {
+			return channel, nil
+		}
+	}
+	return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "Channel %s not found.", name)
+}
+
+// ctfFromChannel returns the CTF the channel belongs to.
+func (s *Server) ctfFromChannel(channelID snowflake.ID) (*ctfbot.CTF, error) {
+	parent, err := s.parentChannel(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ctfFromCategory(context.TODO(), parent)
+}
+
+// ctfFromCategory returns the CTF of the category. Active CTFs created before
+// their category ID was stored are found by name.
+func (s *Server) ctfFromCategory(ctx context.Context, category discord.GuildChannel) (*ctfbot.CTF, error) {
+	ctf, err := s.CTFService.FindCTFByCategoryID(ctx, category.ID().String())
+	if ctfbot.ErrorCode(err) != ctfbot.ENOTFOUND {
+		return ctf, err
+	}
+
+	// Archived CTFs have no category either, don't mistake a category named
+	// after one of them for it.
+	ctf, err = s.CTFService.FindCTFByName(ctx, category.Name())
+	if err != nil {
+		return nil, err
+	} else if ctf.CategoryID != "" || ctf.Status != ctfbot.CTFStatusActive {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "CTF not found.")
+	}
+	return ctf, nil
+}
+
+// findCTFByID returns the CTF identified by id.
+func (s *Server) findCTFByID(ctx context.Context, id int) (*ctfbot.CTF, error) {
+	ctfs, _, err := s.CTFService.FindCTFs(ctx, ctfbot.CTFFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(ctfs) == 0 {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "CTF not found.")
+	}
+	return ctfs[0], nil
+}
+
+// categoryChannel returns the category of the CTF.
+func (s *Server) categoryChannel(ctf *ctfbot.CTF) (discord.GuildChannel, error) {
+	if ctf.CategoryID != "" {
+		return s.cachedChannel(ctf.CategoryID, fmt.Sprintf("Category of `%s` not found.", ctf.Name))
+	}
+
+	// Fall back to the name for CTFs created before the ID was stored.
+	var category discord.GuildChannel
+	s.client.Caches().ChannelsForEach(func(channel discord.GuildChannel) {
+		if channel.Type() == discord.ChannelTypeGuildCategory && channel.Name() == ctf.Name {
+			category = channel
+		}
+	})
+	if category == nil {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "Category of `%s` not found.", ctf.Name)
+	}
+	return category, nil
+}
+
+// generalChannel returns the general channel of the CTF.
+func (s *Server) generalChannel(ctf *ctfbot.CTF) (discord.GuildChannel, error) {
+	if ctf.GeneralChannelID != "" {
+		return s.cachedChannel(ctf.GeneralChannelID, fmt.Sprintf("General channel of `%s` not found.", ctf.Name))
+	}
+
+	category, err := s.categoryChannel(ctf)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.childChannelByName(category.ID(), s.GeneralChannel)
+}
+
+// cachedChannel returns the channel identified by id. The error reports
+// message when it isn't found.
+func (s *Server) cachedChannel(id string, message string) (discord.GuildChannel, error) {
+	channelID, err := snowflake.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, found := s.client.Caches().Channel(channelID)
+	if !found {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "%s", message)
+	}
+	return channel, nil
+}
+
 // cheer() is a simple function that returns a random cheer phrase.
 func cheer() string {
 	cheers := []string{
@@ -0,0 +1,56 @@
+package ctfbot
+
+import (
+	"context"
+	"time"
+)
+
+// Supported scoring platforms.
+const (
+	PlatformCTFd = "ctfd"
+	PlatformRCTF = "rctf"
+)
+
+// Platform represents the scoring platform a CTF is played on.
+type Platform interface {
+	// Retrieves the challenges of the CTF.
+	FindChallenges(ctx context.Context) ([]*PlatformChallenge, error)
+
+	// Retrieves the challenges solved by our team.
+	FindSolves(ctx context.Context) ([]*PlatformSolve, error)
+
+	// Retrieves the position of our team on the scoreboard.
+	FindStanding(ctx context.Context) (*Standing, error)
+
+	// Retrieves the top teams of the scoreboard, up to limit.
+	FindScoreboard(ctx context.Context, limit int) ([]*Standing, error)
+}
+
+// PlatformChallenge represents a challenge as listed by the platform.
+type PlatformChallenge struct {
+	ID       string
+	Name     string
+	Category string
+	Points   int
+
+	// How many teams solved the challenge.
+	Solves int
+}
+
+// PlatformSolve represents a challenge solved by our team, as seen by the platform.
+type PlatformSolve struct {
+	ChallengeID   string
+	ChallengeName string
+
+	// Name of the player who submitted the flag, if known.
+	UserName string
+
+	SolvedAt time.Time
+}
+
+// Standing represents the position of a team on the scoreboard.
+type Standing struct {
+	TeamName string
+	Position int
+	Score    int
+}
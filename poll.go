@@ -0,0 +1,168 @@
+package ctfbot
+
+import (
+	"context"
+	"time"
+)
+
+// Poll modes.
+const (
+	// Everyone votes for a single candidate.
+	PollSingle = "single"
+
+	// Everyone votes for as many candidates as they like.
+	PollApproval = "approval"
+
+	// Everyone ranks their favourite candidates, the winner is found by
+	// instant runoff.
+	PollRanked = "ranked"
+)
+
+// Poll represents a vote on which upcoming CTF to play.
+type Poll struct {
+	ID int
+
+	// One of PollSingle, PollApproval or PollRanked.
+	Mode string
+
+	// Discord IDs of the message listing the candidates, and of its channel.
+	ChannelID string
+	MessageID string
+
+	// CTFTime IDs of the candidate events, in the order they are listed.
+	EventIDs []int
+
+	// Titles of the candidate events, in the same order. Empty for polls
+	// created before they were stored.
+	Titles []string
+
+	// When the votes are tallied.
+	Deadline time.Time
+
+	// Whether the CTF of the winning event is created once the votes are
+	// tallied.
+	AutoCreate bool
+
+	// When the votes were tallied. Zero if the poll is still open.
+	ClosedAt time.Time
+
+	CreatedAt time.Time
+}
+
+func (p *Poll) Validate() error {
+	if p.ChannelID == "" || p.MessageID == "" {
+		return Errorf(EINVALID, "Poll message required.")
+	}
+
+	switch p.Mode {
+	case PollSingle, PollApproval, PollRanked:
+	default:
+		return Errorf(EINVALID, "Unknown poll mode `%s`.", p.Mode)
+	}
+
+	if len(p.EventIDs) == 0 {
+		return Errorf(EINVALID, "At least one candidate required.")
+	}
+
+	if len(p.Titles) != 0 && len(p.Titles) != len(p.EventIDs) {
+		return Errorf(EINVALID, "Every candidate requires a title.")
+	}
+
+	if p.Deadline.IsZero() {
+		return Errorf(EINVALID, "Deadline required.")
+	}
+
+	return nil
+}
+
+// Vote represents a member voting for one of the candidates of a poll.
+type Vote struct {
+	ID     int
+	PollID int
+
+	// Discord ID of the voter.
+	UserID string
+
+	// Index of the candidate in the EventIDs of the poll.
+	Choice int
+
+	// Preference of the voter for the candidate in ranked polls, starting
+	// from 1. Zero in the other modes.
+	Rank int
+
+	CreatedAt time.Time
+}
+
+func (v *Vote) Validate() error {
+	if v.PollID == 0 {
+		return Errorf(EINVALID, "Poll required.")
+	}
+
+	if v.UserID == "" {
+		return Errorf(EINVALID, "User required.")
+	}
+
+	if v.Choice < 0 {
+		return Errorf(EINVALID, "Invalid choice.")
+	}
+
+	if v.Rank < 0 {
+		return Errorf(EINVALID, "Invalid rank.")
+	}
+
+	return nil
+}
+
+type PollService interface {
+	// Creates a new poll.
+	CreatePoll(ctx context.Context, poll *Poll) error
+
+	// Retrieves a poll by ID.
+	FindPollByID(ctx context.Context, id int) (*Poll, error)
+
+	// Retrieves a poll by the Discord ID of its message.
+	FindPollByMessageID(ctx context.Context, messageID string) (*Poll, error)
+
+	// Retrieves a list of polls by filter.
+	FindPolls(ctx context.Context, filter PollFilter) ([]*Poll, int, error)
+
+	// Retrieves the open polls past their deadline, according to the clock
+	// of the service.
+	FindDuePolls(ctx context.Context) ([]*Poll, error)
+
+	// Marks a poll as closed. Closed polls don't take votes anymore.
+	ClosePoll(ctx context.Context, id int) (*Poll, error)
+
+	// Replaces the votes of a user in a poll, which has to be open. An
+	// empty list of votes withdraws them.
+	CastVotes(ctx context.Context, pollID int, userID string, votes []*Vote) error
+
+	// Retrieves a list of votes by filter.
+	FindVotes(ctx context.Context, filter VoteFilter) ([]*Vote, int, error)
+}
+
+// PollFilter represents a filter passed to FindPolls().
+type PollFilter struct {
+	ID        *int
+	MessageID *string
+	Closed    *bool
+
+	// Only polls with a deadline by this time.
+	DueBy *time.Time
+
+	// Limit and offset.
+	Limit  int
+	Offset int
+}
+
+// VoteFilter represents a filter passed to FindVotes().
+type VoteFilter struct {
+	ID     *int
+	PollID *int
+	UserID *string
+	Choice *int
+
+	// Limit and offset.
+	Limit  int
+	Offset int
+}
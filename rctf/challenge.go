@@ -0,0 +1,54 @@
+package rctf
+
+type Challenge struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	Author      string `json:"author"`
+	Points      int    `json:"points"`
+	Solves      int    `json:"solves"`
+	SortWeight  int    `json:"sortWeight"`
+}
+
+// Solve represents a challenge solved by a team. CreatedAt is expressed in
+// milliseconds since the Unix epoch.
+type Solve struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Category  string `json:"category"`
+	Points    int    `json:"points"`
+	Solves    int    `json:"solves"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+// Team represents the team we are logged in as.
+type Team struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Division      string  `json:"division"`
+	Score         int     `json:"score"`
+	GlobalPlace   int     `json:"globalPlace"`
+	DivisionPlace int     `json:"divisionPlace"`
+	Solves        []Solve `json:"solves"`
+}
+
+// Leaderboard represents a page of the scoreboard.
+type Leaderboard struct {
+	Total       int                `json:"total"`
+	Leaderboard []LeaderboardEntry `json:"leaderboard"`
+}
+
+type LeaderboardEntry struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+}
+
+// response is the envelope wrapping every rCTF API response. Kind tells
+// whether the request was successful, e.g. "goodChallenges" or "badToken".
+type response struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Data    any    `json:"data"`
+}
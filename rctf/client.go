@@ -0,0 +1,221 @@
+package rctf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/havce/ctfbot"
+)
+
+// Client talks to the API of an rCTF instance. It logs in with the team
+// token, and then authenticates every request with the returned auth token.
+type Client struct {
+	c *http.Client
+
+	// Base URL of the rCTF instance, e.g. https://2020.redpwn.net.
+	URL string
+
+	// Team token, as found in the login link of the team.
+	TeamToken string
+
+	mu        sync.Mutex
+	authToken string
+}
+
+func NewClient(baseURL string) *Client {
+	return &Client{
+		c:   http.DefaultClient,
+		URL: baseURL,
+	}
+}
+
+// Ensure client implements the platform interface.
+var _ ctfbot.Platform = (*Client)(nil)
+
+// Login exchanges the team token for an auth token. It is called
+// automatically by the other methods when needed.
+func (c *Client) Login(ctx context.Context) error {
+	body, err := json.Marshal(map[string]string{"teamToken": c.TeamToken})
+	if err != nil {
+		return err
+	}
+
+	var data struct {
+		AuthToken string `json:"authToken"`
+	}
+	if err := c.do(ctx, "POST", "/api/v1/auth/login", "", bytes.NewReader(body), &data); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.authToken = data.AuthToken
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *Client) FindChallenges(ctx context.Context) ([]*ctfbot.PlatformChallenge, error) {
+	challenges := make([]*Challenge, 0)
+	if err := c.get(ctx, "/api/v1/challs", &challenges); err != nil {
+		return nil, err
+	}
+
+	chals := make([]*ctfbot.PlatformChallenge, 0, len(challenges))
+	for _, challenge := range challenges {
+		chals = append(chals, &ctfbot.PlatformChallenge{
+			ID:       challenge.ID,
+			Name:     challenge.Name,
+			Category: challenge.Category,
+			Points:   challenge.Points,
+			Solves:   challenge.Solves,
+		})
+	}
+	return chals, nil
+}
+
+func (c *Client) FindSolves(ctx context.Context) ([]*ctfbot.PlatformSolve, error) {
+	team, err := c.FindTeam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	solves := make([]*ctfbot.PlatformSolve, 0, len(team.Solves))
+	for _, solve := range team.Solves {
+		solves = append(solves, &ctfbot.PlatformSolve{
+			ChallengeID:   solve.ID,
+			ChallengeName: solve.Name,
+			SolvedAt:      time.UnixMilli(solve.CreatedAt),
+		})
+	}
+	return solves, nil
+}
+
+func (c *Client) FindStanding(ctx context.Context) (*ctfbot.Standing, error) {
+	team, err := c.FindTeam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ctfbot.Standing{
+		TeamName: team.Name,
+		Position: team.GlobalPlace,
+		Score:    team.Score,
+	}, nil
+}
+
+// rCTF doesn't hand out more than 100 leaderboard entries at once.
+const maxLeaderboardLimit = 100
+
+func (c *Client) FindScoreboard(ctx context.Context, limit int) ([]*ctfbot.Standing, error) {
+	limit = min(limit, maxLeaderboardLimit)
+
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", "0")
+
+	var leaderboard Leaderboard
+	if err := c.get(ctx, "/api/v1/leaderboard/now?"+query.Encode(), &leaderboard); err != nil {
+		return nil, err
+	}
+
+	standings := make([]*ctfbot.Standing, 0, len(leaderboard.Leaderboard))
+	for i, entry := range leaderboard.Leaderboard {
+		standings = append(standings, &ctfbot.Standing{
+			TeamName: entry.Name,
+			Position: i + 1,
+			Score:    entry.Score,
+		})
+	}
+	return standings, nil
+}
+
+// FindTeam retrieves the team we are logged in as.
+func (c *Client) FindTeam(ctx context.Context) (*Team, error) {
+	team := &Team{}
+	if err := c.get(ctx, "/api/v1/users/me", team); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// get performs an authenticated GET request to path and decodes the data of
+// the response into v. It logs in first if needed, and once more if the auth
+// token has expired.
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	c.mu.Lock()
+	authToken := c.authToken
+	c.mu.Unlock()
+
+	if authToken != "" {
+		err := c.do(ctx, "GET", path, authToken, nil, v)
+		if ctfbot.ErrorCode(err) != ctfbot.EUNAUTHORIZED {
+			return err
+		}
+	}
+
+	if err := c.Login(ctx); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	authToken = c.authToken
+	c.mu.Unlock()
+
+	return c.do(ctx, "GET", path, authToken, nil, v)
+}
+
+// do performs a request to path and decodes the data of the response into v.
+func (c *Client) do(ctx context.Context, method, path, authToken string, body io.Reader, v any) error {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return err
+	}
+
+	// Keep the query string out of the escaped path.
+	ref, err := url.Parse(path)
+	if err != nil {
+		return err
+	}
+
+	u = u.JoinPath(ref.Path)
+	u.RawQuery = ref.RawQuery
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+authToken)
+	}
+
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	r := response{Data: v}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return ctfbot.Errorf(ctfbot.EINVALID, "Couldn't understand the rCTF response. Is the URL right?")
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return ctfbot.Errorf(ctfbot.EUNAUTHORIZED, "rCTF refused our credentials: %s", r.Message)
+	} else if resp.StatusCode == http.StatusNotFound {
+		return ctfbot.Errorf(ctfbot.ENOTFOUND, "rCTF resource not found: %s", r.Message)
+	} else if resp.StatusCode < 200 || resp.StatusCode >= 300 || !strings.HasPrefix(r.Kind, "good") {
+		return ctfbot.Errorf(ctfbot.EINVALID, "rCTF request was not successful: %s", r.Message)
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package rctf_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/havce/ctfbot"
+	"github.com/havce/ctfbot/rctf"
+	"github.com/havce/ctfbot/rctf/rctftest"
+)
+
+func TestClient_Login(t *testing.T) {
+	s := rctftest.NewServer("team-token")
+	defer s.Close()
+
+	t.Run("OK", func(t *testing.T) {
+		c := rctf.NewClient(s.URL)
+		c.TeamToken = "team-token"
+		if err := c.Login(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("ErrBadTeamToken", func(t *testing.T) {
+		c := rctf.NewClient(s.URL)
+		c.TeamToken = "bad"
+		if err := c.Login(context.Background()); ctfbot.ErrorCode(err) != ctfbot.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure the client logs in by itself, and fails without a valid team
+	// token instead of calling the API unauthenticated.
+	t.Run("ErrNotLoggedIn", func(t *testing.T) {
+		c := rctf.NewClient(s.URL)
+		c.TeamToken = "bad"
+		if _, err := c.FindChallenges(context.Background()); ctfbot.ErrorCode(err) != ctfbot.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestClient_FindChallenges(t *testing.T) {
+	s := rctftest.NewServer("team-token")
+	defer s.Close()
+
+	s.Challenges = []rctf.Challenge{
+		{ID: "pwn-baby", Name: "baby pwn", Category: "pwn", Points: 100, Solves: 42},
+		{ID: "crypto-rsa", Name: "rsa", Category: "crypto", Points: 500},
+	}
+
+	c := rctf.NewClient(s.URL)
+	c.TeamToken = "team-token"
+
+	chals, err := c.FindChallenges(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	} else if got, want := len(chals), 2; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	}
+
+	if got, want := *chals[0], (ctfbot.PlatformChallenge{ID: "pwn-baby", Name: "baby pwn", Category: "pwn", Points: 100, Solves: 42}); got != want {
+		t.Fatalf("challenge=%+v, want %+v", got, want)
+	}
+}
+
+func TestClient_FindSolves(t *testing.T) {
+	s := rctftest.NewServer("team-token")
+	defer s.Close()
+
+	solvedAt := time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC)
+	s.Team = rctf.Team{
+		Name: "havce",
+		Solves: []rctf.Solve{
+			{ID: "crypto-rsa", Name: "rsa", CreatedAt: solvedAt.UnixMilli()},
+		},
+	}
+
+	c := rctf.NewClient(s.URL)
+	c.TeamToken = "team-token"
+
+	solves, err := c.FindSolves(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	} else if got, want := len(solves), 1; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	}
+
+	if got, want := solves[0].ChallengeID, "crypto-rsa"; got != want {
+		t.Fatalf("ChallengeID=%q, want %q", got, want)
+	} else if got, want := solves[0].ChallengeName, "rsa"; got != want {
+		t.Fatalf("ChallengeName=%q, want %q", got, want)
+	} else if got := solves[0].SolvedAt; !got.Equal(solvedAt) {
+		t.Fatalf("SolvedAt=%s, want %s", got, solvedAt)
+	}
+}
+
+func TestClient_FindStanding(t *testing.T) {
+	s := rctftest.NewServer("team-token")
+	defer s.Close()
+
+	s.Team = rctf.Team{Name: "havce", Score: 1337, GlobalPlace: 3, DivisionPlace: 1}
+
+	c := rctf.NewClient(s.URL)
+	c.TeamToken = "team-token"
+
+	standing, err := c.FindStanding(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	} else if got, want := *standing, (ctfbot.Standing{TeamName: "havce", Position: 3, Score: 1337}); got != want {
+		t.Fatalf("standing=%+v, want %+v", got, want)
+	}
+}
+
+func TestClient_FindScoreboard(t *testing.T) {
+	s := rctftest.NewServer("team-token")
+	defer s.Close()
+
+	for i := 0; i < 150; i++ {
+		s.Leaderboard = append(s.Leaderboard, rctf.LeaderboardEntry{Name: "team", Score: 1000 - i})
+	}
+	s.Leaderboard[1].Name = "second"
+
+	c := rctf.NewClient(s.URL)
+	c.TeamToken = "team-token"
+
+	t.Run("OK", func(t *testing.T) {
+		standings, err := c.FindScoreboard(context.Background(), 10)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := len(standings), 10; got != want {
+			t.Fatalf("len=%d, want %d", got, want)
+		} else if got, want := *standings[1], (ctfbot.Standing{TeamName: "second", Position: 2, Score: 999}); got != want {
+			t.Fatalf("standing=%+v, want %+v", got, want)
+		}
+	})
+
+	// Ensure the limit is capped to what rCTF accepts.
+	t.Run("MaxLimit", func(t *testing.T) {
+		standings, err := c.FindScoreboard(context.Background(), 1000)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := len(standings), 100; got != want {
+			t.Fatalf("len=%d, want %d", got, want)
+		}
+	})
+}
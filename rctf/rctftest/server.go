@@ -0,0 +1,123 @@
+// Package rctftest provides a fake rCTF instance for testing clients.
+package rctftest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/havce/ctfbot/rctf"
+)
+
+// AuthToken is the auth token handed out by the fake server on login.
+const AuthToken = "rctftest-auth-token"
+
+// Server is a fake rCTF instance backed by an httptest.Server. Set its fields
+// to control the responses.
+type Server struct {
+	*httptest.Server
+
+	mu sync.Mutex
+
+	// Team token accepted by the login endpoint.
+	TeamToken string
+
+	Team        rctf.Team
+	Challenges  []rctf.Challenge
+	Leaderboard []rctf.LeaderboardEntry
+}
+
+// NewServer starts a fake rCTF instance accepting teamToken. The caller
+// should call Close when finished, to shut it down.
+func NewServer(teamToken string) *Server {
+	s := &Server{
+		TeamToken: teamToken,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/auth/login", s.handleLogin)
+	mux.HandleFunc("GET /api/v1/challs", s.authenticated(s.handleChallenges))
+	mux.HandleFunc("GET /api/v1/users/me", s.authenticated(s.handleMe))
+	mux.HandleFunc("GET /api/v1/leaderboard/now", s.handleLeaderboard)
+
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		TeamToken string `json:"teamToken"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeResponse(w, http.StatusBadRequest, "badBody", nil)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if body.TeamToken != s.TeamToken {
+		writeResponse(w, http.StatusUnauthorized, "badTokenVerification", nil)
+		return
+	}
+
+	writeResponse(w, http.StatusOK, "goodLogin", map[string]string{"authToken": AuthToken})
+}
+
+func (s *Server) handleChallenges(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeResponse(w, http.StatusOK, "goodChallenges", s.Challenges)
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeResponse(w, http.StatusOK, "goodUserData", s.Team)
+}
+
+func (s *Server) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 0 || limit > 100 {
+		writeResponse(w, http.StatusBadRequest, "badBody", nil)
+		return
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	entries := s.Leaderboard[min(offset, len(s.Leaderboard)):]
+	entries = entries[:min(limit, len(entries))]
+
+	writeResponse(w, http.StatusOK, "goodLeaderboard", rctf.Leaderboard{
+		Total:       len(s.Leaderboard),
+		Leaderboard: entries,
+	})
+}
+
+// authenticated rejects requests without the auth token handed out on login.
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ") != AuthToken {
+			writeResponse(w, http.StatusUnauthorized, "badToken", nil)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func writeResponse(w http.ResponseWriter, status int, kind string, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"kind":    kind,
+		"message": kind,
+		"data":    data,
+	})
+}
@@ -0,0 +1,84 @@
+package ctfbot
+
+import (
+	"context"
+	"time"
+)
+
+// Kinds of reminder.
+const (
+	ReminderStart = "start"
+	ReminderEnd   = "end"
+)
+
+// How late a reminder can still be sent. Reminders overdue for longer, e.g.
+// because the bot was down, are dropped.
+const ReminderGracePeriod = 15 * time.Minute
+
+// Reminder represents a scheduled ping of the players of a CTF.
+type Reminder struct {
+	ID    int
+	CTFID int
+
+	// Either ReminderStart or ReminderEnd.
+	Kind string
+
+	// When the reminder is due.
+	At time.Time
+
+	// When the reminder was sent. Zero if it is still pending.
+	SentAt time.Time
+
+	CreatedAt time.Time
+}
+
+func (r *Reminder) Validate() error {
+	if r.CTFID == 0 {
+		return Errorf(EINVALID, "CTF required.")
+	}
+
+	if r.Kind != ReminderStart && r.Kind != ReminderEnd {
+		return Errorf(EINVALID, "Unknown reminder kind `%s`.", r.Kind)
+	}
+
+	if r.At.IsZero() {
+		return Errorf(EINVALID, "Reminder time required.")
+	}
+
+	return nil
+}
+
+type ReminderService interface {
+	// Creates a new reminder. Creating the same reminder twice fails with
+	// ECONFLICT.
+	CreateReminder(ctx context.Context, reminder *Reminder) error
+
+	// Retrieves a list of reminders by filter.
+	FindReminders(ctx context.Context, filter ReminderFilter) ([]*Reminder, int, error)
+
+	// Retrieves the pending reminders that are due, according to the
+	// clock of the service. Reminders overdue by more than
+	// ReminderGracePeriod are marked as sent instead.
+	FindDueReminders(ctx context.Context) ([]*Reminder, error)
+
+	// Marks a reminder as sent.
+	MarkReminderSent(ctx context.Context, id int) (*Reminder, error)
+
+	// Permanently deletes a reminder.
+	DeleteReminder(ctx context.Context, id int) error
+}
+
+// ReminderFilter represents a filter passed to FindReminders().
+type ReminderFilter struct {
+	ID    *int
+	CTFID *int
+	Kind  *string
+	Sent  *bool
+
+	// Only reminders due by this time.
+	DueBy *time.Time
+
+	// Limit and offset.
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,50 @@
+package ctfbot
+
+import (
+	"context"
+	"time"
+)
+
+// Snapshot represents the standing of our team on the scoreboard of a CTF at
+// a given time.
+type Snapshot struct {
+	ID    int
+	CTFID int
+
+	// Position on the scoreboard, starting from 1.
+	Position int
+	Score    int
+
+	CreatedAt time.Time
+}
+
+func (s *Snapshot) Validate() error {
+	if s.CTFID == 0 {
+		return Errorf(EINVALID, "CTF required.")
+	}
+
+	if s.Position < 1 {
+		return Errorf(EINVALID, "Position must be at least 1.")
+	}
+
+	return nil
+}
+
+type SnapshotService interface {
+	// Creates a new snapshot.
+	CreateSnapshot(ctx context.Context, snapshot *Snapshot) error
+
+	// Retrieves a list of snapshots by filter. The most recent snapshots
+	// come first.
+	FindSnapshots(ctx context.Context, filter SnapshotFilter) ([]*Snapshot, int, error)
+}
+
+// SnapshotFilter represents a filter passed to FindSnapshots().
+type SnapshotFilter struct {
+	ID    *int
+	CTFID *int
+
+	// Limit and offset.
+	Limit  int
+	Offset int
+}
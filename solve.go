@@ -0,0 +1,60 @@
+package ctfbot
+
+import (
+	"context"
+	"time"
+)
+
+// Solve represents a challenge being flagged by a member of the team.
+type Solve struct {
+	ID          int
+	ChallengeID int
+
+	// Discord ID of the user who flagged the challenge.
+	UserID string
+
+	// Whether the challenge was first blooded.
+	Blood bool
+
+	// The flag itself, if it was provided.
+	Flag string
+
+	// Time of the solve. Defaults to the creation time.
+	SolvedAt time.Time
+}
+
+func (s *Solve) Validate() error {
+	if s.ChallengeID == 0 {
+		return Errorf(EINVALID, "Challenge required.")
+	}
+
+	if s.UserID == "" {
+		return Errorf(EINVALID, "Solver required.")
+	}
+
+	return nil
+}
+
+type SolveService interface {
+	// Creates a new solve and marks the challenge as solved.
+	CreateSolve(ctx context.Context, solve *Solve) error
+
+	// Retrieves a list of solves by filter.
+	FindSolves(ctx context.Context, filter SolveFilter) ([]*Solve, int, error)
+
+	// Permanently deletes a solve and marks the challenge as unsolved.
+	DeleteSolve(ctx context.Context, id int) error
+}
+
+// SolveFilter represents a filter passed to FindSolves().
+type SolveFilter struct {
+	ID          *int
+	ChallengeID *int
+	CTFID       *int
+	UserID      *string
+	Blood       *bool
+
+	// Limit and offset.
+	Limit  int
+	Offset int
+}
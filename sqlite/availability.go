@@ -0,0 +1,328 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/havce/ctfbot"
+)
+
+type AvailabilityService struct {
+	db *DB
+}
+
+func NewAvailabilityService(db *DB) *AvailabilityService {
+	return &AvailabilityService{
+		db: db,
+	}
+}
+
+func (s *AvailabilityService) CreateAvailabilityPoll(ctx context.Context, poll *ctfbot.AvailabilityPoll) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Create poll.
+	if err := createAvailabilityPoll(ctx, tx, poll); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *AvailabilityService) FindAvailabilityPollByMessageID(ctx context.Context, messageID string) (*ctfbot.AvailabilityPoll, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Fetch poll object.
+	return findAvailabilityPollByMessageID(ctx, tx, messageID)
+}
+
+func (s *AvailabilityService) FindAvailabilityPolls(ctx context.Context, filter ctfbot.AvailabilityPollFilter) ([]*ctfbot.AvailabilityPoll, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	return findAvailabilityPolls(ctx, tx, filter)
+}
+
+func (s *AvailabilityService) SetAvailability(ctx context.Context, pollID int, userID string, slots []int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Replace the responses of the user.
+	if err := setAvailability(ctx, tx, pollID, userID, slots); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *AvailabilityService) FindAvailabilityResponses(ctx context.Context, filter ctfbot.AvailabilityResponseFilter) ([]*ctfbot.AvailabilityResponse, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	return findAvailabilityResponses(ctx, tx, filter)
+}
+
+func findAvailabilityPollByID(ctx context.Context, tx *Tx, id int) (*ctfbot.AvailabilityPoll, error) {
+	polls, _, err := findAvailabilityPolls(ctx, tx, ctfbot.AvailabilityPollFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(polls) == 0 {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "Poll not found.")
+	}
+	return polls[0], nil
+}
+
+func findAvailabilityPollByMessageID(ctx context.Context, tx *Tx, messageID string) (*ctfbot.AvailabilityPoll, error) {
+	polls, _, err := findAvailabilityPolls(ctx, tx, ctfbot.AvailabilityPollFilter{MessageID: &messageID})
+	if err != nil {
+		return nil, err
+	} else if len(polls) == 0 {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "Poll not found.")
+	}
+	return polls[0], nil
+}
+
+func findAvailabilityPolls(ctx context.Context, tx *Tx, filter ctfbot.AvailabilityPollFilter) (_ []*ctfbot.AvailabilityPoll, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+
+	if v := filter.MessageID; v != nil {
+		where, args = append(where, "message_id = ?"), append(args, *v)
+	}
+
+	// Execue query with limiting WHERE clause and LIMIT/OFFSET injected.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    id,
+		    channel_id,
+		    message_id,
+		    event_id,
+		    title,
+		    start,
+		    slot_length,
+		    slots,
+		    created_at,
+		    COUNT(*) OVER()
+		FROM availability_polls
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into AvailabilityPoll objects.
+	polls := make([]*ctfbot.AvailabilityPoll, 0)
+	for rows.Next() {
+		var poll ctfbot.AvailabilityPoll
+		var slotLength int64
+		if err := rows.Scan(
+			&poll.ID,
+			&poll.ChannelID,
+			&poll.MessageID,
+			&poll.EventID,
+			&poll.Title,
+			(*NullTime)(&poll.Start),
+			&slotLength,
+			&poll.Slots,
+			(*NullTime)(&poll.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+
+		// Slot lengths are stored in seconds.
+		poll.SlotLength = time.Duration(slotLength) * time.Second
+		polls = append(polls, &poll)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return polls, n, nil
+}
+
+// createAvailabilityPoll creates a new availability poll.
+func createAvailabilityPoll(ctx context.Context, tx *Tx, poll *ctfbot.AvailabilityPoll) error {
+	// Set timestamp to current time.
+	poll.CreatedAt = tx.now
+
+	// Perform basic field validation.
+	if err := poll.Validate(); err != nil {
+		return err
+	}
+
+	// Insert row into database.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO availability_polls (
+			channel_id,
+			message_id,
+			event_id,
+			title,
+			start,
+			slot_length,
+			slots,
+			created_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		poll.ChannelID,
+		poll.MessageID,
+		poll.EventID,
+		poll.Title,
+		(*NullTime)(&poll.Start),
+		int64(poll.SlotLength/time.Second),
+		poll.Slots,
+		(*NullTime)(&poll.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new poll ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	poll.ID = int(id)
+
+	return nil
+}
+
+// setAvailability replaces the slots a user is available in.
+func setAvailability(ctx context.Context, tx *Tx, pollID int, userID string, slots []int) error {
+	poll, err := findAvailabilityPollByID(ctx, tx, pollID)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the slots fit the poll before touching anything.
+	responses, seen := make([]*ctfbot.AvailabilityResponse, 0, len(slots)), map[int]bool{}
+	for _, slot := range slots {
+		response := &ctfbot.AvailabilityResponse{
+			PollID:    pollID,
+			UserID:    userID,
+			Slot:      slot,
+			CreatedAt: tx.now,
+		}
+		if err := response.Validate(); err != nil {
+			return err
+		} else if slot >= poll.Slots {
+			return ctfbot.Errorf(ctfbot.EINVALID, "Invalid slot.")
+		}
+
+		if !seen[slot] {
+			seen[slot] = true
+			responses = append(responses, response)
+		}
+	}
+
+	// Remove the previous responses of the user.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM availability_responses WHERE poll_id = ? AND user_id = ?`, pollID, userID); err != nil {
+		return FormatError(err)
+	}
+
+	for _, response := range responses {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO availability_responses (
+				poll_id,
+				user_id,
+				slot,
+				created_at
+			)
+			VALUES (?, ?, ?, ?)
+		`,
+			response.PollID,
+			response.UserID,
+			response.Slot,
+			(*NullTime)(&response.CreatedAt),
+		)
+		if err != nil {
+			return FormatError(err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		response.ID = int(id)
+	}
+	return nil
+}
+
+func findAvailabilityResponses(ctx context.Context, tx *Tx, filter ctfbot.AvailabilityResponseFilter) (_ []*ctfbot.AvailabilityResponse, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.PollID; v != nil {
+		where, args = append(where, "poll_id = ?"), append(args, *v)
+	}
+
+	if v := filter.UserID; v != nil {
+		where, args = append(where, "user_id = ?"), append(args, *v)
+	}
+
+	// Execue query with limiting WHERE clause and LIMIT/OFFSET injected.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    id,
+		    poll_id,
+		    user_id,
+		    slot,
+		    created_at,
+		    COUNT(*) OVER()
+		FROM availability_responses
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY slot ASC, id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into AvailabilityResponse objects.
+	responses := make([]*ctfbot.AvailabilityResponse, 0)
+	for rows.Next() {
+		var response ctfbot.AvailabilityResponse
+		if err := rows.Scan(
+			&response.ID,
+			&response.PollID,
+			&response.UserID,
+			&response.Slot,
+			(*NullTime)(&response.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		responses = append(responses, &response)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return responses, n, nil
+}
@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/havce/ctfbot"
+)
+
+type CacheService struct {
+	db *DB
+}
+
+func NewCacheService(db *DB) *CacheService {
+	return &CacheService{
+		db: db,
+	}
+}
+
+func (s *CacheService) FindCacheEntry(ctx context.Context, key string) (*ctfbot.CacheEntry, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Fetch cache entry.
+	return findCacheEntry(ctx, tx, key)
+}
+
+func (s *CacheService) SetCacheEntry(ctx context.Context, entry *ctfbot.CacheEntry) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Create or replace cache entry.
+	if err := setCacheEntry(ctx, tx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *CacheService) DeleteCacheEntries(ctx context.Context, before time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Remove rows from database.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM cache WHERE fetched_at < ?`, (*NullTime)(&before)); err != nil {
+		return FormatError(err)
+	}
+	return tx.Commit()
+}
+
+func findCacheEntry(ctx context.Context, tx *Tx, key string) (*ctfbot.CacheEntry, error) {
+	var entry ctfbot.CacheEntry
+	if err := tx.QueryRowContext(ctx, `
+		SELECT
+		    key,
+		    value,
+		    fetched_at
+		FROM cache
+		WHERE key = ?
+	`,
+		key,
+	).Scan(
+		&entry.Key,
+		&entry.Value,
+		(*NullTime)(&entry.FetchedAt),
+	); errors.Is(err, sql.ErrNoRows) {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "Cache entry not found.")
+	} else if err != nil {
+		return nil, FormatError(err)
+	}
+	return &entry, nil
+}
+
+// setCacheEntry creates a cache entry, or replaces the one with the same key.
+func setCacheEntry(ctx context.Context, tx *Tx, entry *ctfbot.CacheEntry) error {
+	// Perform basic field validation.
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	// Insert or replace row into database.
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO cache (
+			key,
+			value,
+			fetched_at
+		)
+		VALUES (?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			value = excluded.value,
+			fetched_at = excluded.fetched_at
+	`,
+		entry.Key,
+		entry.Value,
+		(*NullTime)(&entry.FetchedAt),
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
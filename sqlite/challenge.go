@@ -0,0 +1,318 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+
+	"github.com/havce/ctfbot"
+)
+
+type ChallengeService struct {
+	db *DB
+}
+
+func NewChallengeService(db *DB) *ChallengeService {
+	return &ChallengeService{
+		db: db,
+	}
+}
+
+func (s *ChallengeService) FindChallengeByID(ctx context.Context, id int) (*ctfbot.Challenge, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Fetch challenge object.
+	return findChallengeByID(ctx, tx, id)
+}
+
+func (s *ChallengeService) FindChallengeByChannelID(ctx context.Context, channelID string) (*ctfbot.Challenge, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Fetch challenge object.
+	return findChallengeByChannelID(ctx, tx, channelID)
+}
+
+func (s *ChallengeService) FindChallenges(ctx context.Context, filter ctfbot.ChallengeFilter) ([]*ctfbot.Challenge, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	return findChallenges(ctx, tx, filter)
+}
+
+func (s *ChallengeService) CreateChallenge(ctx context.Context, chal *ctfbot.Challenge) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Create challenge.
+	if err := createChallenge(ctx, tx, chal); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *ChallengeService) UpdateChallenge(ctx context.Context, id int, upd ctfbot.ChallengeUpdate) (*ctfbot.Challenge, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Update the challenge object.
+	chal, err := updateChallenge(ctx, tx, id, upd)
+	if err != nil {
+		return chal, err
+	}
+	return chal, tx.Commit()
+}
+
+func (s *ChallengeService) DeleteChallenge(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := deleteChallenge(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func findChallengeByID(ctx context.Context, tx *Tx, id int) (*ctfbot.Challenge, error) {
+	chals, _, err := findChallenges(ctx, tx, ctfbot.ChallengeFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(chals) == 0 {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "Challenge not found.")
+	}
+	return chals[0], nil
+}
+
+func findChallengeByChannelID(ctx context.Context, tx *Tx, channelID string) (*ctfbot.Challenge, error) {
+	chals, _, err := findChallenges(ctx, tx, ctfbot.ChallengeFilter{ChannelID: &channelID})
+	if err != nil {
+		return nil, err
+	} else if len(chals) == 0 {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "Challenge not found.")
+	}
+	return chals[0], nil
+}
+
+func findChallenges(ctx context.Context, tx *Tx, filter ctfbot.ChallengeFilter) (_ []*ctfbot.Challenge, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+
+	if v := filter.CTFID; v != nil {
+		where, args = append(where, "ctf_id = ?"), append(args, *v)
+	}
+
+	if v := filter.Name; v != nil {
+		where, args = append(where, "name = ?"), append(args, *v)
+	}
+
+	if v := filter.Category; v != nil {
+		where, args = append(where, "category = ?"), append(args, *v)
+	}
+
+	if v := filter.ChannelID; v != nil {
+		where, args = append(where, "channel_id = ?"), append(args, *v)
+	}
+
+	if v := filter.Solved; v != nil {
+		where, args = append(where, "solved = ?"), append(args, *v)
+	}
+
+	// Execue query with limiting WHERE clause and LIMIT/OFFSET injected.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    id,
+		    ctf_id,
+		    name,
+		    category,
+		    points,
+		    channel_id,
+		    solved,
+		    blood,
+		    created_at,
+		    updated_at,
+		    COUNT(*) OVER()
+		FROM challenges
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into Challenge objects.
+	chals := make([]*ctfbot.Challenge, 0)
+	for rows.Next() {
+		var chal ctfbot.Challenge
+		if err := rows.Scan(
+			&chal.ID,
+			&chal.CTFID,
+			&chal.Name,
+			&chal.Category,
+			&chal.Points,
+			&chal.ChannelID,
+			&chal.Solved,
+			&chal.Blood,
+			(*NullTime)(&chal.CreatedAt),
+			(*NullTime)(&chal.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		chals = append(chals, &chal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return chals, n, nil
+}
+
+// createChallenge creates a new challenge.
+func createChallenge(ctx context.Context, tx *Tx, chal *ctfbot.Challenge) error {
+	// Set timestamps to current time.
+	chal.CreatedAt = tx.now
+	chal.UpdatedAt = chal.CreatedAt
+
+	// Perform basic field validation.
+	if err := chal.Validate(); err != nil {
+		return err
+	}
+
+	// Insert row into database.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO challenges (
+			ctf_id,
+			name,
+			category,
+			points,
+			channel_id,
+			solved,
+			blood,
+			created_at,
+			updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		chal.CTFID,
+		chal.Name,
+		chal.Category,
+		chal.Points,
+		chal.ChannelID,
+		chal.Solved,
+		chal.Blood,
+		(*NullTime)(&chal.CreatedAt),
+		(*NullTime)(&chal.UpdatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new challenge ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	chal.ID = int(id)
+
+	return nil
+}
+
+// updateChallenge updates a challenge by ID. Returns the new state of the
+// challenge after update.
+func updateChallenge(ctx context.Context, tx *Tx, id int, upd ctfbot.ChallengeUpdate) (*ctfbot.Challenge, error) {
+	// Fetch current object state.
+	chal, err := findChallengeByID(ctx, tx, id)
+	if err != nil {
+		return chal, err
+	}
+
+	// Update fields, if set.
+	if v := upd.Name; v != nil {
+		chal.Name = *v
+	}
+
+	if v := upd.Category; v != nil {
+		chal.Category = *v
+	}
+
+	if v := upd.Points; v != nil {
+		chal.Points = *v
+	}
+
+	if v := upd.Solved; v != nil {
+		chal.Solved = *v
+	}
+
+	if v := upd.Blood; v != nil {
+		chal.Blood = *v
+	}
+
+	chal.UpdatedAt = tx.now
+
+	// Perform basic field validation.
+	if err := chal.Validate(); err != nil {
+		return chal, err
+	}
+
+	// Execute update query.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE challenges
+		SET name = ?,
+			category = ?,
+			points = ?,
+			solved = ?,
+			blood = ?,
+		    updated_at = ?
+		WHERE id = ?
+	`,
+		chal.Name,
+		chal.Category,
+		chal.Points,
+		chal.Solved,
+		chal.Blood,
+		(*NullTime)(&chal.UpdatedAt),
+		id,
+	); err != nil {
+		return chal, FormatError(err)
+	}
+
+	return chal, nil
+}
+
+// deleteChallenge permanently deletes a challenge by ID.
+func deleteChallenge(ctx context.Context, tx *Tx, id int) error {
+	if _, err := findChallengeByID(ctx, tx, id); err != nil {
+		return err
+	}
+
+	// Remove row from database.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM challenges WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
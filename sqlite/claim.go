@@ -0,0 +1,187 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+
+	"github.com/havce/ctfbot"
+)
+
+type ClaimService struct {
+	db *DB
+}
+
+func NewClaimService(db *DB) *ClaimService {
+	return &ClaimService{
+		db: db,
+	}
+}
+
+func (s *ClaimService) FindClaims(ctx context.Context, filter ctfbot.ClaimFilter) ([]*ctfbot.Claim, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	return findClaims(ctx, tx, filter)
+}
+
+func (s *ClaimService) CreateClaim(ctx context.Context, claim *ctfbot.Claim) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Create claim.
+	if err := createClaim(ctx, tx, claim); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *ClaimService) DeleteClaim(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := deleteClaim(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func findClaimByID(ctx context.Context, tx *Tx, id int) (*ctfbot.Claim, error) {
+	claims, _, err := findClaims(ctx, tx, ctfbot.ClaimFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(claims) == 0 {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "Claim not found.")
+	}
+	return claims[0], nil
+}
+
+func findClaims(ctx context.Context, tx *Tx, filter ctfbot.ClaimFilter) (_ []*ctfbot.Claim, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "cl.id = ?"), append(args, *v)
+	}
+
+	if v := filter.ChallengeID; v != nil {
+		where, args = append(where, "cl.challenge_id = ?"), append(args, *v)
+	}
+
+	if v := filter.CTFID; v != nil {
+		where, args = append(where, "c.ctf_id = ?"), append(args, *v)
+	}
+
+	if v := filter.UserID; v != nil {
+		where, args = append(where, "cl.user_id = ?"), append(args, *v)
+	}
+
+	// Execue query with limiting WHERE clause and LIMIT/OFFSET injected.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    cl.id,
+		    cl.challenge_id,
+		    cl.user_id,
+		    cl.started_at,
+		    COUNT(*) OVER()
+		FROM claims cl
+		INNER JOIN challenges c ON c.id = cl.challenge_id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY cl.started_at ASC, cl.id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into Claim objects.
+	claims := make([]*ctfbot.Claim, 0)
+	for rows.Next() {
+		var claim ctfbot.Claim
+		if err := rows.Scan(
+			&claim.ID,
+			&claim.ChallengeID,
+			&claim.UserID,
+			(*NullTime)(&claim.StartedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		claims = append(claims, &claim)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return claims, n, nil
+}
+
+// createClaim creates a new claim.
+func createClaim(ctx context.Context, tx *Tx, claim *ctfbot.Claim) error {
+	// Set timestamp to current time.
+	claim.StartedAt = tx.now
+
+	// Perform basic field validation.
+	if err := claim.Validate(); err != nil {
+		return err
+	}
+
+	// Users can only work once on the same challenge.
+	if _, n, err := findClaims(ctx, tx, ctfbot.ClaimFilter{
+		ChallengeID: &claim.ChallengeID,
+		UserID:      &claim.UserID,
+	}); err != nil {
+		return err
+	} else if n != 0 {
+		return ctfbot.Errorf(ctfbot.ECONFLICT, "You're already working on this challenge.")
+	}
+
+	// Insert row into database.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO claims (
+			challenge_id,
+			user_id,
+			started_at
+		)
+		VALUES (?, ?, ?)
+	`,
+		claim.ChallengeID,
+		claim.UserID,
+		(*NullTime)(&claim.StartedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new claim ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	claim.ID = int(id)
+
+	return nil
+}
+
+// deleteClaim permanently deletes a claim by ID.
+func deleteClaim(ctx context.Context, tx *Tx, id int) error {
+	if _, err := findClaimByID(ctx, tx, id); err != nil {
+		return err
+	}
+
+	// Remove row from database.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM claims WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+
+	"github.com/havce/ctfbot"
+)
+
+type CredentialsService struct {
+	db *DB
+}
+
+func NewCredentialsService(db *DB) *CredentialsService {
+	return &CredentialsService{
+		db: db,
+	}
+}
+
+func (s *CredentialsService) FindCredentialsByCTFID(ctx context.Context, ctfID int) (*ctfbot.Credentials, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Fetch credentials object.
+	return findCredentialsByCTFID(ctx, tx, ctfID)
+}
+
+func (s *CredentialsService) FindCredentials(ctx context.Context, filter ctfbot.CredentialsFilter) ([]*ctfbot.Credentials, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	return findCredentials(ctx, tx, filter)
+}
+
+func (s *CredentialsService) SetCredentials(ctx context.Context, creds *ctfbot.Credentials) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Set credentials.
+	if err := setCredentials(ctx, tx, creds); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func findCredentialsByCTFID(ctx context.Context, tx *Tx, ctfID int) (*ctfbot.Credentials, error) {
+	creds, _, err := findCredentials(ctx, tx, ctfbot.CredentialsFilter{CTFID: &ctfID})
+	if err != nil {
+		return nil, err
+	} else if len(creds) == 0 {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "No credentials were set for this CTF.")
+	}
+	return creds[0], nil
+}
+
+func findCredentials(ctx context.Context, tx *Tx, filter ctfbot.CredentialsFilter) (_ []*ctfbot.Credentials, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.CTFID; v != nil {
+		where, args = append(where, "ctf_id = ?"), append(args, *v)
+	}
+
+	if v := filter.Platform; v != nil {
+		where, args = append(where, "platform = ?"), append(args, *v)
+	}
+
+	// Execue query with limiting WHERE clause and LIMIT/OFFSET injected.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    id,
+		    ctf_id,
+		    platform,
+		    url,
+		    team_name,
+		    token,
+		    password,
+		    created_at,
+		    updated_at,
+		    COUNT(*) OVER()
+		FROM credentials
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into Credentials objects.
+	credentials := make([]*ctfbot.Credentials, 0)
+	for rows.Next() {
+		var creds ctfbot.Credentials
+		if err := rows.Scan(
+			&creds.ID,
+			&creds.CTFID,
+			&creds.Platform,
+			&creds.URL,
+			&creds.TeamName,
+			&creds.Token,
+			&creds.Password,
+			(*NullTime)(&creds.CreatedAt),
+			(*NullTime)(&creds.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+
+		// Decrypt secrets.
+		if creds.Token, err = tx.db.decrypt(creds.Token); err != nil {
+			return nil, 0, err
+		}
+		if creds.Password, err = tx.db.decrypt(creds.Password); err != nil {
+			return nil, 0, err
+		}
+
+		credentials = append(credentials, &creds)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return credentials, n, nil
+}
+
+// setCredentials creates or replaces the credentials of a CTF.
+func setCredentials(ctx context.Context, tx *Tx, creds *ctfbot.Credentials) error {
+	// Set timestamps to current time, keeping the creation time if the
+	// credentials are being replaced.
+	creds.CreatedAt = tx.now
+	creds.UpdatedAt = tx.now
+	existing, err := findCredentialsByCTFID(ctx, tx, creds.CTFID)
+	if err == nil {
+		creds.CreatedAt = existing.CreatedAt
+	} else if ctfbot.ErrorCode(err) != ctfbot.ENOTFOUND {
+		return err
+	}
+
+	// Perform basic field validation.
+	if err := creds.Validate(); err != nil {
+		return err
+	}
+
+	// Encrypt secrets.
+	token, err := tx.db.encrypt(creds.Token)
+	if err != nil {
+		return err
+	}
+	password, err := tx.db.encrypt(creds.Password)
+	if err != nil {
+		return err
+	}
+
+	// Insert row into database, replacing the existing one.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO credentials (
+			ctf_id,
+			platform,
+			url,
+			team_name,
+			token,
+			password,
+			created_at,
+			updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (ctf_id) DO UPDATE SET
+			platform = excluded.platform,
+			url = excluded.url,
+			team_name = excluded.team_name,
+			token = excluded.token,
+			password = excluded.password,
+			updated_at = excluded.updated_at
+	`,
+		creds.CTFID,
+		creds.Platform,
+		creds.URL,
+		creds.TeamName,
+		token,
+		password,
+		(*NullTime)(&creds.CreatedAt),
+		(*NullTime)(&creds.UpdatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back credentials ID into caller argument. The ID of replaced
+	// credentials doesn't change.
+	if existing != nil {
+		creds.ID = existing.ID
+		return nil
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	creds.ID = int(id)
+
+	return nil
+}
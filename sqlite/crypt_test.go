@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/havce/ctfbot"
+)
+
+func TestDB_Encrypt(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		db := NewDB(":memory:")
+		db.EncryptionKey = key
+
+		ciphertext, err := db.encrypt("hunter2")
+		if err != nil {
+			t.Fatal(err)
+		} else if ciphertext == "hunter2" {
+			t.Fatal("expected plaintext to be encrypted")
+		}
+
+		if plaintext, err := db.decrypt(ciphertext); err != nil {
+			t.Fatal(err)
+		} else if got, want := plaintext, "hunter2"; got != want {
+			t.Fatalf("plaintext=%q, want %q", got, want)
+		}
+
+		// Ensure the nonce is random, so equal secrets don't look equal.
+		if other, err := db.encrypt("hunter2"); err != nil {
+			t.Fatal(err)
+		} else if other == ciphertext {
+			t.Fatal("expected different ciphertexts")
+		}
+	})
+
+	// Ensure empty strings are stored as they are.
+	t.Run("Empty", func(t *testing.T) {
+		db := NewDB(":memory:")
+		db.EncryptionKey = key
+
+		if ciphertext, err := db.encrypt(""); err != nil {
+			t.Fatal(err)
+		} else if ciphertext != "" {
+			t.Fatalf("ciphertext=%q, want empty", ciphertext)
+		}
+
+		if plaintext, err := db.decrypt(""); err != nil {
+			t.Fatal(err)
+		} else if plaintext != "" {
+			t.Fatalf("plaintext=%q, want empty", plaintext)
+		}
+	})
+
+	t.Run("ErrNoKey", func(t *testing.T) {
+		db := NewDB(":memory:")
+		if _, err := db.encrypt("hunter2"); ctfbot.ErrorCode(err) != ctfbot.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("ErrWrongKey", func(t *testing.T) {
+		db := NewDB(":memory:")
+		db.EncryptionKey = key
+
+		ciphertext, err := db.encrypt("hunter2")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		db.EncryptionKey = bytes.Repeat([]byte{0x24}, 32)
+		if _, err := db.decrypt(ciphertext); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("ErrTampered", func(t *testing.T) {
+		db := NewDB(":memory:")
+		db.EncryptionKey = key
+
+		ciphertext, err := db.encrypt("hunter2")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Flip a bit of the nonce, of the ciphertext and of the tag.
+		for _, i := range []int{0, len(sealed) - 20, len(sealed) - 1} {
+			tampered := bytes.Clone(sealed)
+			tampered[i] ^= 1
+			if _, err := db.decrypt(base64.StdEncoding.EncodeToString(tampered)); err == nil {
+				t.Fatalf("expected error when byte %d is modified", i)
+			}
+		}
+	})
+
+	t.Run("ErrTruncated", func(t *testing.T) {
+		db := NewDB(":memory:")
+		db.EncryptionKey = key
+
+		if _, err := db.decrypt(base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 
 	"github.com/havce/ctfbot"
@@ -28,6 +29,17 @@ func (s *CTFService) FindCTFByName(ctx context.Context, name string) (*ctfbot.CT
 	return findCTFByName(ctx, tx, name)
 }
 
+func (s *CTFService) FindCTFByCategoryID(ctx context.Context, categoryID string) (*ctfbot.CTF, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Fetch CTF object.
+	return findCTFByCategoryID(ctx, tx, categoryID)
+}
+
 func (s *CTFService) FindCTFs(ctx context.Context, filter ctfbot.CTFFilter) ([]*ctfbot.CTF, int, error) {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -38,6 +50,38 @@ func (s *CTFService) FindCTFs(ctx context.Context, filter ctfbot.CTFFilter) ([]*
 	return findCTFs(ctx, tx, filter)
 }
 
+func (s *CTFService) FindCTFsToClose(ctx context.Context) ([]*ctfbot.CTF, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// CTFs are due according to the transaction time.
+	status := ctfbot.CTFStatusActive
+	ctfs, _, err := findCTFs(ctx, tx, ctfbot.CTFFilter{
+		Status:  &status,
+		CloseBy: &tx.now,
+	})
+	return ctfs, err
+}
+
+func (s *CTFService) FindCTFsToArchive(ctx context.Context) ([]*ctfbot.CTF, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// CTFs are due according to the transaction time.
+	status := ctfbot.CTFStatusActive
+	ctfs, _, err := findCTFs(ctx, tx, ctfbot.CTFFilter{
+		Status:    &status,
+		ArchiveBy: &tx.now,
+	})
+	return ctfs, err
+}
+
 func (s *CTFService) CreateCTF(ctx context.Context, ctf *ctfbot.CTF) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -90,6 +134,21 @@ func findCTFByName(ctx context.Context, tx *Tx, name string) (*ctfbot.CTF, error
 	return ctfs[0], nil
 }
 
+func findCTFByCategoryID(ctx context.Context, tx *Tx, categoryID string) (*ctfbot.CTF, error) {
+	// Rows created before the category was stored have no category ID.
+	if categoryID == "" {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "CTF not found.")
+	}
+
+	ctfs, _, err := findCTFs(ctx, tx, ctfbot.CTFFilter{CategoryID: &categoryID})
+	if err != nil {
+		return nil, err
+	} else if len(ctfs) == 0 {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "CTF not found.")
+	}
+	return ctfs[0], nil
+}
+
 func findCTFs(ctx context.Context, tx *Tx, filter ctfbot.CTFFilter) (_ []*ctfbot.CTF, n int, err error) {
 	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
 	// Values are appended to an arg list to avoid SQL injection.
@@ -116,15 +175,49 @@ func findCTFs(ctx context.Context, tx *Tx, filter ctfbot.CTFFilter) (_ []*ctfbot
 		where, args = append(where, "can_join = ?"), append(args, canJoin)
 	}
 
+	if v := filter.Status; v != nil {
+		where, args = append(where, "status = ?"), append(args, *v)
+	}
+
+	if v := filter.CTFTimeID; v != nil {
+		where, args = append(where, "ctftime_id = ?"), append(args, *v)
+	}
+
+	if v := filter.CategoryID; v != nil {
+		where, args = append(where, "category_id = ?"), append(args, *v)
+	}
+
+	if v := filter.CloseBy; v != nil {
+		where, args = append(where, "close_at IS NOT NULL AND close_at <= ?"), append(args, (*NullTime)(v))
+	}
+
+	if v := filter.ArchiveBy; v != nil {
+		where, args = append(where, "archive_at IS NOT NULL AND archive_at <= ?"), append(args, (*NullTime)(v))
+	}
+
 	// Execue query with limiting WHERE clause and LIMIT/OFFSET injected.
 	rows, err := tx.QueryContext(ctx, `
 		SELECT 
 		    id,
 		    name,
 		    start,
+		    finish,
 		    role_id,
 			can_join,
+			category_id,
+			registration_channel_id,
+			general_channel_id,
+			registration_message_id,
+			status,
+			board_message_id,
+			archived_channel_ids,
+			close_at,
+			archive_at,
+			ctftime_id,
 			ctftime_url,
+			weight,
+			format,
+			url,
 		    created_at,
 		    updated_at,
 		    COUNT(*) OVER()
@@ -143,19 +236,39 @@ func findCTFs(ctx context.Context, tx *Tx, filter ctfbot.CTFFilter) (_ []*ctfbot
 	ctfs := make([]*ctfbot.CTF, 0)
 	for rows.Next() {
 		var ctf ctfbot.CTF
+		var archivedChannelIDs string
 		if err := rows.Scan(
 			&ctf.ID,
 			&ctf.Name,
 			(*NullTime)(&ctf.Start),
+			(*NullTime)(&ctf.Finish),
 			&ctf.RoleID,
 			&ctf.CanJoin,
+			&ctf.CategoryID,
+			&ctf.RegistrationChannelID,
+			&ctf.GeneralChannelID,
+			&ctf.RegistrationMessageID,
+			&ctf.Status,
+			&ctf.BoardMessageID,
+			&archivedChannelIDs,
+			(*NullTime)(&ctf.CloseAt),
+			(*NullTime)(&ctf.ArchiveAt),
+			&ctf.CTFTimeID,
 			&ctf.CTFTimeURL,
+			&ctf.Weight,
+			&ctf.Format,
+			&ctf.URL,
 			(*NullTime)(&ctf.CreatedAt),
 			(*NullTime)(&ctf.UpdatedAt),
 			&n,
 		); err != nil {
 			return nil, 0, err
 		}
+
+		// Channel IDs are stored as a JSON array.
+		if err := json.Unmarshal([]byte(archivedChannelIDs), &ctf.ArchivedChannelIDs); err != nil {
+			return nil, 0, err
+		}
 		ctfs = append(ctfs, &ctf)
 	}
 	if err := rows.Err(); err != nil {
@@ -171,29 +284,67 @@ func createCTF(ctx context.Context, tx *Tx, ctf *ctfbot.CTF) error {
 	ctf.CreatedAt = tx.now
 	ctf.UpdatedAt = ctf.CreatedAt
 
+	// New CTFs are active, unless told otherwise.
+	if ctf.Status == "" {
+		ctf.Status = ctfbot.CTFStatusActive
+	}
+
 	// Perform basic field validation.
 	if err := ctf.Validate(); err != nil {
 		return err
 	}
 
+	archivedChannelIDs, err := marshalChannelIDs(ctf.ArchivedChannelIDs)
+	if err != nil {
+		return err
+	}
+
 	// Insert row into database.
 	result, err := tx.ExecContext(ctx, `
 		INSERT INTO ctfs (
 			name,
 			start,
+			finish,
 			role_id,
 			can_join,
+			category_id,
+			registration_channel_id,
+			general_channel_id,
+			registration_message_id,
+			status,
+			board_message_id,
+			archived_channel_ids,
+			close_at,
+			archive_at,
+			ctftime_id,
 			ctftime_url,
+			weight,
+			format,
+			url,
 			created_at,
 			updated_at
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		ctf.Name,
 		(*NullTime)(&ctf.Start),
+		(*NullTime)(&ctf.Finish),
 		ctf.RoleID,
 		ctf.CanJoin,
+		ctf.CategoryID,
+		ctf.RegistrationChannelID,
+		ctf.GeneralChannelID,
+		ctf.RegistrationMessageID,
+		ctf.Status,
+		ctf.BoardMessageID,
+		archivedChannelIDs,
+		(*NullTime)(&ctf.CloseAt),
+		(*NullTime)(&ctf.ArchiveAt),
+		ctf.CTFTimeID,
 		ctf.CTFTimeURL,
+		ctf.Weight,
+		ctf.Format,
+		ctf.URL,
 		(*NullTime)(&ctf.CreatedAt),
 		(*NullTime)(&ctf.UpdatedAt),
 	)
@@ -232,14 +383,70 @@ func updateCTF(ctx context.Context, tx *Tx, name string, upd ctfbot.CTFUpdate) (
 		ctf.RoleID = *v
 	}
 
+	if v := upd.CategoryID; v != nil {
+		ctf.CategoryID = *v
+	}
+
+	if v := upd.RegistrationChannelID; v != nil {
+		ctf.RegistrationChannelID = *v
+	}
+
+	if v := upd.GeneralChannelID; v != nil {
+		ctf.GeneralChannelID = *v
+	}
+
+	if v := upd.RegistrationMessageID; v != nil {
+		ctf.RegistrationMessageID = *v
+	}
+
+	if v := upd.Status; v != nil {
+		ctf.Status = *v
+	}
+
+	if v := upd.BoardMessageID; v != nil {
+		ctf.BoardMessageID = *v
+	}
+
+	if v := upd.ArchivedChannelIDs; v != nil {
+		ctf.ArchivedChannelIDs = *v
+	}
+
+	if v := upd.CTFTimeID; v != nil {
+		ctf.CTFTimeID = *v
+	}
+
 	if v := upd.CTFTimeURL; v != nil {
 		ctf.CTFTimeURL = *v
 	}
 
+	if v := upd.Weight; v != nil {
+		ctf.Weight = *v
+	}
+
+	if v := upd.Format; v != nil {
+		ctf.Format = *v
+	}
+
+	if v := upd.URL; v != nil {
+		ctf.URL = *v
+	}
+
 	if v := upd.Start; v != nil {
 		ctf.Start = *v
 	}
 
+	if v := upd.Finish; v != nil {
+		ctf.Finish = *v
+	}
+
+	if v := upd.CloseAt; v != nil {
+		ctf.CloseAt = *v
+	}
+
+	if v := upd.ArchiveAt; v != nil {
+		ctf.ArchiveAt = *v
+	}
+
 	ctf.UpdatedAt = tx.now
 
 	// Perform basic field validation.
@@ -247,20 +454,53 @@ func updateCTF(ctx context.Context, tx *Tx, name string, upd ctfbot.CTFUpdate) (
 		return ctf, err
 	}
 
+	archivedChannelIDs, err := marshalChannelIDs(ctf.ArchivedChannelIDs)
+	if err != nil {
+		return ctf, err
+	}
+
 	// Execute update query.
 	if _, err := tx.ExecContext(ctx, `
 		UPDATE ctfs
 		SET can_join = ?,
+			status = ?,
+			board_message_id = ?,
+			archived_channel_ids = ?,
+			close_at = ?,
+			archive_at = ?,
 			start = ?,
+			finish = ?,
+			ctftime_id = ?,
 			ctftime_url = ?,
+			weight = ?,
+			format = ?,
+			url = ?,
 			role_id = ?,
+			category_id = ?,
+			registration_channel_id = ?,
+			general_channel_id = ?,
+			registration_message_id = ?,
 		    updated_at = ?
 		WHERE name = ?
 	`,
 		ctf.CanJoin,
+		ctf.Status,
+		ctf.BoardMessageID,
+		archivedChannelIDs,
+		(*NullTime)(&ctf.CloseAt),
+		(*NullTime)(&ctf.ArchiveAt),
 		(*NullTime)(&ctf.Start),
+		(*NullTime)(&ctf.Finish),
+		ctf.CTFTimeID,
 		ctf.CTFTimeURL,
+		ctf.Weight,
+		ctf.Format,
+		ctf.URL,
 		ctf.RoleID,
+		ctf.CategoryID,
+		ctf.RegistrationChannelID,
+		ctf.GeneralChannelID,
+		ctf.RegistrationMessageID,
 		(*NullTime)(&ctf.UpdatedAt),
 		name,
 	); err != nil {
@@ -282,3 +522,17 @@ func deleteCTF(ctx context.Context, tx *Tx, name string) error {
 	}
 	return nil
 }
+
+// marshalChannelIDs encodes a list of channel IDs as a JSON array. A nil list
+// is encoded as an empty one.
+func marshalChannelIDs(ids []string) (string, error) {
+	if ids == nil {
+		ids = []string{}
+	}
+
+	buf, err := json.Marshal(ids)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
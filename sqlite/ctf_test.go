@@ -0,0 +1,57 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/havce/ctfbot"
+	"github.com/havce/ctfbot/sqlite"
+)
+
+func TestCTFService_FindCTFsToClose(t *testing.T) {
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+
+	ctx := context.Background()
+	now := time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC)
+	db.Now = func() time.Time { return now }
+
+	s := sqlite.NewCTFService(db)
+	for i, ctf := range []*ctfbot.CTF{
+		{Name: "due", CloseAt: now.Add(-time.Minute), ArchiveAt: now.Add(time.Hour)},
+		{Name: "later", CloseAt: now.Add(time.Minute), ArchiveAt: now.Add(-time.Minute)},
+		{Name: "unscheduled"},
+		{Name: "archived", Status: ctfbot.CTFStatusArchived, CloseAt: now.Add(-time.Minute), ArchiveAt: now.Add(-time.Minute)},
+	} {
+		ctf.RoleID, ctf.Start, ctf.Finish = string(rune('1'+i)), now, now.Add(48*time.Hour)
+		if err := s.CreateCTF(ctx, ctf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Ensure only active CTFs due by the clock of the service are returned.
+	if ctfs, err := s.FindCTFsToClose(ctx); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(ctfs), 1; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	} else if got, want := ctfs[0].Name, "due"; got != want {
+		t.Fatalf("Name=%q, want %q", got, want)
+	}
+
+	if ctfs, err := s.FindCTFsToArchive(ctx); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(ctfs), 1; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	} else if got, want := ctfs[0].Name, "later"; got != want {
+		t.Fatalf("Name=%q, want %q", got, want)
+	}
+
+	// Everything is due once the clock moves.
+	now = now.Add(2 * time.Hour)
+	if ctfs, err := s.FindCTFsToClose(ctx); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(ctfs), 2; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	}
+}
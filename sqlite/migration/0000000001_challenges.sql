@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS challenges (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  ctf_id     INTEGER NOT NULL REFERENCES ctfs (id) ON DELETE CASCADE,
+  name       TEXT NOT NULL,
+  channel_id TEXT NOT NULL UNIQUE,
+  solved     BOOLEAN NOT NULL DEFAULT 0,
+  blood      BOOLEAN NOT NULL DEFAULT 0,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+
+  UNIQUE (ctf_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS challenges_ctf_id_idx ON challenges (ctf_id);
@@ -0,0 +1,2 @@
+ALTER TABLE challenges ADD COLUMN category TEXT NOT NULL DEFAULT '';
+ALTER TABLE challenges ADD COLUMN points INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS solves (
+  id           INTEGER PRIMARY KEY AUTOINCREMENT,
+  challenge_id INTEGER NOT NULL UNIQUE REFERENCES challenges (id) ON DELETE CASCADE,
+  user_id      TEXT NOT NULL,
+  blood        BOOLEAN NOT NULL DEFAULT 0,
+  flag         TEXT NOT NULL DEFAULT '',
+  solved_at    TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS solves_user_id_idx ON solves (user_id);
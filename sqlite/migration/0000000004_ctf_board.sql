@@ -0,0 +1 @@
+ALTER TABLE ctfs ADD COLUMN board_message_id TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS claims (
+  id           INTEGER PRIMARY KEY AUTOINCREMENT,
+  challenge_id INTEGER NOT NULL REFERENCES challenges (id) ON DELETE CASCADE,
+  user_id      TEXT NOT NULL,
+  started_at   TEXT NOT NULL,
+
+  UNIQUE (challenge_id, user_id)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS credentials (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  ctf_id     INTEGER NOT NULL UNIQUE REFERENCES ctfs (id) ON DELETE CASCADE,
+  platform   TEXT NOT NULL,
+  url        TEXT NOT NULL,
+  team_name  TEXT NOT NULL,
+  token      TEXT NOT NULL,
+  password   TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS snapshots (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  ctf_id     INTEGER NOT NULL REFERENCES ctfs (id) ON DELETE CASCADE,
+  position   INTEGER NOT NULL,
+  score      INTEGER NOT NULL,
+  created_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS snapshots_ctf_id_idx ON snapshots (ctf_id, created_at);
@@ -0,0 +1,5 @@
+ALTER TABLE ctfs ADD COLUMN ctftime_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE ctfs ADD COLUMN finish TEXT;
+ALTER TABLE ctfs ADD COLUMN weight REAL NOT NULL DEFAULT 0;
+ALTER TABLE ctfs ADD COLUMN format TEXT NOT NULL DEFAULT '';
+ALTER TABLE ctfs ADD COLUMN url TEXT NOT NULL DEFAULT '';
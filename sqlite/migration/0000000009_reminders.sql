@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS reminders (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  ctf_id     INTEGER NOT NULL REFERENCES ctfs (id) ON DELETE CASCADE,
+  kind       TEXT NOT NULL,
+  at         TEXT NOT NULL,
+  sent_at    TEXT,
+  created_at TEXT NOT NULL,
+
+  UNIQUE (ctf_id, kind, at)
+);
+
+CREATE INDEX IF NOT EXISTS reminders_at_idx ON reminders (at);
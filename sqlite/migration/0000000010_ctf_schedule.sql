@@ -0,0 +1,2 @@
+ALTER TABLE ctfs ADD COLUMN close_at TEXT;
+ALTER TABLE ctfs ADD COLUMN archive_at TEXT;
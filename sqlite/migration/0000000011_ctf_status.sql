@@ -0,0 +1 @@
+ALTER TABLE ctfs ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
@@ -0,0 +1,6 @@
+ALTER TABLE ctfs ADD COLUMN category_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE ctfs ADD COLUMN registration_channel_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE ctfs ADD COLUMN general_channel_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE ctfs ADD COLUMN registration_message_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS ctfs_category_id_idx ON ctfs (category_id);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS polls (
+  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+  channel_id  TEXT NOT NULL,
+  message_id  TEXT NOT NULL UNIQUE,
+  event_ids   TEXT NOT NULL,
+  deadline    TEXT NOT NULL,
+  auto_create BOOLEAN NOT NULL DEFAULT 0,
+  closed_at   TEXT,
+  created_at  TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS polls_deadline_idx ON polls (deadline);
+
+CREATE TABLE IF NOT EXISTS votes (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  poll_id    INTEGER NOT NULL REFERENCES polls (id) ON DELETE CASCADE,
+  user_id    TEXT NOT NULL,
+  choice     INTEGER NOT NULL,
+  created_at TEXT NOT NULL,
+
+  UNIQUE (poll_id, user_id, choice)
+);
+
+CREATE INDEX IF NOT EXISTS votes_poll_id_idx ON votes (poll_id);
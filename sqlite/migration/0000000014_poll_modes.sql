@@ -0,0 +1,3 @@
+ALTER TABLE polls ADD COLUMN mode TEXT NOT NULL DEFAULT 'approval';
+ALTER TABLE polls ADD COLUMN titles TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE votes ADD COLUMN rank INTEGER NOT NULL DEFAULT 0;
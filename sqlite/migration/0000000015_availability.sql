@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS availability_polls (
+  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+  channel_id  TEXT NOT NULL,
+  message_id  TEXT NOT NULL UNIQUE,
+  event_id    INTEGER NOT NULL DEFAULT 0,
+  title       TEXT NOT NULL,
+  start       TEXT NOT NULL,
+  slot_length INTEGER NOT NULL,
+  slots       INTEGER NOT NULL,
+  created_at  TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS availability_responses (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  poll_id    INTEGER NOT NULL REFERENCES availability_polls (id) ON DELETE CASCADE,
+  user_id    TEXT NOT NULL,
+  slot       INTEGER NOT NULL,
+  created_at TEXT NOT NULL,
+
+  UNIQUE (poll_id, user_id, slot)
+);
+
+CREATE INDEX IF NOT EXISTS availability_responses_poll_id_idx ON availability_responses (poll_id);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS cache (
+  key        TEXT PRIMARY KEY,
+  value      BLOB NOT NULL,
+  fetched_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS cache_fetched_at_idx ON cache (fetched_at);
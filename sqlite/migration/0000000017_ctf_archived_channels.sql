@@ -0,0 +1 @@
+ALTER TABLE ctfs ADD COLUMN archived_channel_ids TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,496 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/havce/ctfbot"
+)
+
+type PollService struct {
+	db *DB
+}
+
+func NewPollService(db *DB) *PollService {
+	return &PollService{
+		db: db,
+	}
+}
+
+func (s *PollService) FindPollByID(ctx context.Context, id int) (*ctfbot.Poll, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Fetch poll object.
+	return findPollByID(ctx, tx, id)
+}
+
+func (s *PollService) FindPollByMessageID(ctx context.Context, messageID string) (*ctfbot.Poll, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Fetch poll object.
+	return findPollByMessageID(ctx, tx, messageID)
+}
+
+func (s *PollService) FindPolls(ctx context.Context, filter ctfbot.PollFilter) ([]*ctfbot.Poll, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	return findPolls(ctx, tx, filter)
+}
+
+func (s *PollService) FindDuePolls(ctx context.Context) ([]*ctfbot.Poll, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Polls are due according to the transaction time.
+	closed := false
+	polls, _, err := findPolls(ctx, tx, ctfbot.PollFilter{
+		Closed: &closed,
+		DueBy:  &tx.now,
+	})
+	return polls, err
+}
+
+func (s *PollService) CreatePoll(ctx context.Context, poll *ctfbot.Poll) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Create poll.
+	if err := createPoll(ctx, tx, poll); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *PollService) ClosePoll(ctx context.Context, id int) (*ctfbot.Poll, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Close the poll.
+	poll, err := closePoll(ctx, tx, id)
+	if err != nil {
+		return poll, err
+	}
+	return poll, tx.Commit()
+}
+
+func (s *PollService) FindVotes(ctx context.Context, filter ctfbot.VoteFilter) ([]*ctfbot.Vote, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	return findVotes(ctx, tx, filter)
+}
+
+func (s *PollService) CastVotes(ctx context.Context, pollID int, userID string, votes []*ctfbot.Vote) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Replace the votes of the user.
+	if err := castVotes(ctx, tx, pollID, userID, votes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func findPollByID(ctx context.Context, tx *Tx, id int) (*ctfbot.Poll, error) {
+	polls, _, err := findPolls(ctx, tx, ctfbot.PollFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(polls) == 0 {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "Poll not found.")
+	}
+	return polls[0], nil
+}
+
+func findPollByMessageID(ctx context.Context, tx *Tx, messageID string) (*ctfbot.Poll, error) {
+	polls, _, err := findPolls(ctx, tx, ctfbot.PollFilter{MessageID: &messageID})
+	if err != nil {
+		return nil, err
+	} else if len(polls) == 0 {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "Poll not found.")
+	}
+	return polls[0], nil
+}
+
+func findPolls(ctx context.Context, tx *Tx, filter ctfbot.PollFilter) (_ []*ctfbot.Poll, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+
+	if v := filter.MessageID; v != nil {
+		where, args = append(where, "message_id = ?"), append(args, *v)
+	}
+
+	if v := filter.Closed; v != nil {
+		if *v {
+			where = append(where, "closed_at IS NOT NULL")
+		} else {
+			where = append(where, "closed_at IS NULL")
+		}
+	}
+
+	if v := filter.DueBy; v != nil {
+		where, args = append(where, "deadline <= ?"), append(args, (*NullTime)(v))
+	}
+
+	// Execue query with limiting WHERE clause and LIMIT/OFFSET injected.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    id,
+		    mode,
+		    channel_id,
+		    message_id,
+		    event_ids,
+		    titles,
+		    deadline,
+		    auto_create,
+		    closed_at,
+		    created_at,
+		    COUNT(*) OVER()
+		FROM polls
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY deadline ASC, id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into Poll objects.
+	polls := make([]*ctfbot.Poll, 0)
+	for rows.Next() {
+		var poll ctfbot.Poll
+		var eventIDs, titles string
+		if err := rows.Scan(
+			&poll.ID,
+			&poll.Mode,
+			&poll.ChannelID,
+			&poll.MessageID,
+			&eventIDs,
+			&titles,
+			(*NullTime)(&poll.Deadline),
+			&poll.AutoCreate,
+			(*NullTime)(&poll.ClosedAt),
+			(*NullTime)(&poll.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+
+		if poll.EventIDs, err = parseEventIDs(eventIDs); err != nil {
+			return nil, 0, err
+		}
+		if err := json.Unmarshal([]byte(titles), &poll.Titles); err != nil {
+			return nil, 0, err
+		}
+		polls = append(polls, &poll)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return polls, n, nil
+}
+
+// createPoll creates a new poll.
+func createPoll(ctx context.Context, tx *Tx, poll *ctfbot.Poll) error {
+	// Set timestamp to current time. Polls start out open.
+	poll.CreatedAt = tx.now
+	poll.ClosedAt = time.Time{}
+
+	// Perform basic field validation.
+	if err := poll.Validate(); err != nil {
+		return err
+	}
+
+	titles, err := json.Marshal(poll.Titles)
+	if err != nil {
+		return err
+	}
+
+	// Insert row into database.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO polls (
+			mode,
+			channel_id,
+			message_id,
+			event_ids,
+			titles,
+			deadline,
+			auto_create,
+			created_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		poll.Mode,
+		poll.ChannelID,
+		poll.MessageID,
+		formatEventIDs(poll.EventIDs),
+		string(titles),
+		(*NullTime)(&poll.Deadline),
+		poll.AutoCreate,
+		(*NullTime)(&poll.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new poll ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	poll.ID = int(id)
+
+	return nil
+}
+
+// closePoll sets the closing time of a poll to the current time. Returns the
+// new state of the poll.
+func closePoll(ctx context.Context, tx *Tx, id int) (*ctfbot.Poll, error) {
+	poll, err := findPollByID(ctx, tx, id)
+	if err != nil {
+		return poll, err
+	} else if !poll.ClosedAt.IsZero() {
+		return poll, ctfbot.Errorf(ctfbot.ECONFLICT, "Poll already closed.")
+	}
+
+	poll.ClosedAt = tx.now
+
+	// Execute update query.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE polls
+		SET closed_at = ?
+		WHERE id = ?
+	`,
+		(*NullTime)(&poll.ClosedAt),
+		id,
+	); err != nil {
+		return poll, FormatError(err)
+	}
+
+	return poll, nil
+}
+
+func findVotes(ctx context.Context, tx *Tx, filter ctfbot.VoteFilter) (_ []*ctfbot.Vote, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+
+	if v := filter.PollID; v != nil {
+		where, args = append(where, "poll_id = ?"), append(args, *v)
+	}
+
+	if v := filter.UserID; v != nil {
+		where, args = append(where, "user_id = ?"), append(args, *v)
+	}
+
+	if v := filter.Choice; v != nil {
+		where, args = append(where, "choice = ?"), append(args, *v)
+	}
+
+	// Execue query with limiting WHERE clause and LIMIT/OFFSET injected.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    id,
+		    poll_id,
+		    user_id,
+		    choice,
+		    rank,
+		    created_at,
+		    COUNT(*) OVER()
+		FROM votes
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY user_id ASC, rank ASC, id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into Vote objects.
+	votes := make([]*ctfbot.Vote, 0)
+	for rows.Next() {
+		var vote ctfbot.Vote
+		if err := rows.Scan(
+			&vote.ID,
+			&vote.PollID,
+			&vote.UserID,
+			&vote.Choice,
+			&vote.Rank,
+			(*NullTime)(&vote.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		votes = append(votes, &vote)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return votes, n, nil
+}
+
+// castVotes replaces the votes of a user in an open poll.
+func castVotes(ctx context.Context, tx *Tx, pollID int, userID string, votes []*ctfbot.Vote) error {
+	poll, err := findPollByID(ctx, tx, pollID)
+	if err != nil {
+		return err
+	} else if !poll.ClosedAt.IsZero() {
+		return ctfbot.Errorf(ctfbot.EINVALID, "The poll is closed.")
+	} else if !poll.Deadline.IsZero() && tx.now.After(poll.Deadline) {
+		// The poll might not have been closed yet by the worker.
+		return ctfbot.Errorf(ctfbot.EINVALID, "The poll is over.")
+	}
+
+	// Make sure the votes fit the poll before touching anything.
+	choices, ranks := map[int]bool{}, map[int]bool{}
+	for _, vote := range votes {
+		vote.PollID, vote.UserID = pollID, userID
+		if err := vote.Validate(); err != nil {
+			return err
+		}
+
+		if vote.Choice >= len(poll.EventIDs) {
+			return ctfbot.Errorf(ctfbot.EINVALID, "Invalid choice.")
+		} else if choices[vote.Choice] {
+			return ctfbot.Errorf(ctfbot.ECONFLICT, "You can't vote twice for the same CTF.")
+		}
+		choices[vote.Choice] = true
+
+		if poll.Mode != ctfbot.PollRanked {
+			vote.Rank = 0
+			continue
+		}
+
+		if vote.Rank == 0 {
+			return ctfbot.Errorf(ctfbot.EINVALID, "Rank required.")
+		} else if ranks[vote.Rank] {
+			return ctfbot.Errorf(ctfbot.ECONFLICT, "You can't rank two CTFs the same.")
+		}
+		ranks[vote.Rank] = true
+	}
+
+	if poll.Mode == ctfbot.PollSingle && len(votes) > 1 {
+		return ctfbot.Errorf(ctfbot.EINVALID, "You can only vote for one CTF.")
+	}
+
+	// Remove the previous votes of the user.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM votes WHERE poll_id = ? AND user_id = ?`, pollID, userID); err != nil {
+		return FormatError(err)
+	}
+
+	for _, vote := range votes {
+		if err := createVote(ctx, tx, vote); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createVote creates a new vote.
+func createVote(ctx context.Context, tx *Tx, vote *ctfbot.Vote) error {
+	// Set timestamp to current time.
+	vote.CreatedAt = tx.now
+
+	// Perform basic field validation.
+	if err := vote.Validate(); err != nil {
+		return err
+	}
+
+	// Insert row into database.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO votes (
+			poll_id,
+			user_id,
+			choice,
+			rank,
+			created_at
+		)
+		VALUES (?, ?, ?, ?, ?)
+	`,
+		vote.PollID,
+		vote.UserID,
+		vote.Choice,
+		vote.Rank,
+		(*NullTime)(&vote.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new vote ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	vote.ID = int(id)
+
+	return nil
+}
+
+// formatEventIDs serializes the candidates of a poll as a comma separated list.
+func formatEventIDs(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
+
+// parseEventIDs is the inverse of formatEventIDs.
+func parseEventIDs(s string) ([]int, error) {
+	ids := []int{}
+	for _, part := range strings.Split(s, ",") {
+		if part == "" {
+			continue
+		}
+
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,52 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/havce/ctfbot"
+	"github.com/havce/ctfbot/sqlite"
+)
+
+func TestPollService_CastVotes(t *testing.T) {
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+
+	ctx := context.Background()
+	now := time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC)
+	db.Now = func() time.Time { return now }
+
+	s := sqlite.NewPollService(db)
+	poll := &ctfbot.Poll{
+		Mode:      ctfbot.PollSingle,
+		ChannelID: "1",
+		MessageID: "2",
+		EventIDs:  []int{100, 200},
+		Deadline:  now.Add(time.Hour),
+	}
+	if err := s.CreatePoll(ctx, poll); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		if err := s.CastVotes(ctx, poll.ID, "alice", []*ctfbot.Vote{{Choice: 1}}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure votes are refused past the deadline, even if the poll wasn't
+	// closed yet.
+	t.Run("ErrDeadline", func(t *testing.T) {
+		now = now.Add(2 * time.Hour)
+		if err := s.CastVotes(ctx, poll.ID, "bob", []*ctfbot.Vote{{Choice: 0}}); ctfbot.ErrorCode(err) != ctfbot.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if votes, _, err := s.FindVotes(ctx, ctfbot.VoteFilter{PollID: &poll.ID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(votes), 1; got != want {
+			t.Fatalf("len=%d, want %d", got, want)
+		}
+	})
+}
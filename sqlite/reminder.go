@@ -0,0 +1,278 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/havce/ctfbot"
+)
+
+type ReminderService struct {
+	db *DB
+}
+
+func NewReminderService(db *DB) *ReminderService {
+	return &ReminderService{
+		db: db,
+	}
+}
+
+func (s *ReminderService) FindReminders(ctx context.Context, filter ctfbot.ReminderFilter) ([]*ctfbot.Reminder, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	return findReminders(ctx, tx, filter)
+}
+
+func (s *ReminderService) FindDueReminders(ctx context.Context) ([]*ctfbot.Reminder, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Reminders are due according to the transaction time.
+	sent := false
+	reminders, _, err := findReminders(ctx, tx, ctfbot.ReminderFilter{
+		Sent:  &sent,
+		DueBy: &tx.now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop the reminders overdue for too long, pinging that late would only
+	// confuse the players.
+	due := make([]*ctfbot.Reminder, 0, len(reminders))
+	for _, reminder := range reminders {
+		if tx.now.Sub(reminder.At) <= ctfbot.ReminderGracePeriod {
+			due = append(due, reminder)
+		} else if _, err := markReminderSent(ctx, tx, reminder.ID); err != nil {
+			return nil, err
+		}
+	}
+	return due, tx.Commit()
+}
+
+func (s *ReminderService) CreateReminder(ctx context.Context, reminder *ctfbot.Reminder) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Create reminder.
+	if err := createReminder(ctx, tx, reminder); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *ReminderService) MarkReminderSent(ctx context.Context, id int) (*ctfbot.Reminder, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Mark the reminder as sent.
+	reminder, err := markReminderSent(ctx, tx, id)
+	if err != nil {
+		return reminder, err
+	}
+	return reminder, tx.Commit()
+}
+
+func (s *ReminderService) DeleteReminder(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := deleteReminder(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func findReminderByID(ctx context.Context, tx *Tx, id int) (*ctfbot.Reminder, error) {
+	reminders, _, err := findReminders(ctx, tx, ctfbot.ReminderFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(reminders) == 0 {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "Reminder not found.")
+	}
+	return reminders[0], nil
+}
+
+func findReminders(ctx context.Context, tx *Tx, filter ctfbot.ReminderFilter) (_ []*ctfbot.Reminder, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+
+	if v := filter.CTFID; v != nil {
+		where, args = append(where, "ctf_id = ?"), append(args, *v)
+	}
+
+	if v := filter.Kind; v != nil {
+		where, args = append(where, "kind = ?"), append(args, *v)
+	}
+
+	if v := filter.Sent; v != nil {
+		if *v {
+			where = append(where, "sent_at IS NOT NULL")
+		} else {
+			where = append(where, "sent_at IS NULL")
+		}
+	}
+
+	if v := filter.DueBy; v != nil {
+		where, args = append(where, "at <= ?"), append(args, (*NullTime)(v))
+	}
+
+	// Execue query with limiting WHERE clause and LIMIT/OFFSET injected.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    id,
+		    ctf_id,
+		    kind,
+		    at,
+		    sent_at,
+		    created_at,
+		    COUNT(*) OVER()
+		FROM reminders
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY at ASC, id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into Reminder objects.
+	reminders := make([]*ctfbot.Reminder, 0)
+	for rows.Next() {
+		var reminder ctfbot.Reminder
+		if err := rows.Scan(
+			&reminder.ID,
+			&reminder.CTFID,
+			&reminder.Kind,
+			(*NullTime)(&reminder.At),
+			(*NullTime)(&reminder.SentAt),
+			(*NullTime)(&reminder.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		reminders = append(reminders, &reminder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return reminders, n, nil
+}
+
+// createReminder creates a new reminder.
+func createReminder(ctx context.Context, tx *Tx, reminder *ctfbot.Reminder) error {
+	// Set timestamp to current time. Reminders start out pending.
+	reminder.CreatedAt = tx.now
+	reminder.SentAt = time.Time{}
+
+	// Perform basic field validation.
+	if err := reminder.Validate(); err != nil {
+		return err
+	}
+
+	// Don't remind twice of the same thing.
+	reminders, _, err := findReminders(ctx, tx, ctfbot.ReminderFilter{
+		CTFID: &reminder.CTFID,
+		Kind:  &reminder.Kind,
+	})
+	if err != nil {
+		return err
+	}
+	for _, other := range reminders {
+		if other.At.Equal(reminder.At.UTC().Truncate(time.Second)) {
+			return ctfbot.Errorf(ctfbot.ECONFLICT, "Reminder already scheduled.")
+		}
+	}
+
+	// Insert row into database.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO reminders (
+			ctf_id,
+			kind,
+			at,
+			created_at
+		)
+		VALUES (?, ?, ?, ?)
+	`,
+		reminder.CTFID,
+		reminder.Kind,
+		(*NullTime)(&reminder.At),
+		(*NullTime)(&reminder.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new reminder ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	reminder.ID = int(id)
+
+	return nil
+}
+
+// markReminderSent sets the sent time of a reminder to the current time.
+// Returns the new state of the reminder.
+func markReminderSent(ctx context.Context, tx *Tx, id int) (*ctfbot.Reminder, error) {
+	reminder, err := findReminderByID(ctx, tx, id)
+	if err != nil {
+		return reminder, err
+	} else if !reminder.SentAt.IsZero() {
+		return reminder, ctfbot.Errorf(ctfbot.ECONFLICT, "Reminder already sent.")
+	}
+
+	reminder.SentAt = tx.now
+
+	// Execute update query.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE reminders
+		SET sent_at = ?
+		WHERE id = ?
+	`,
+		(*NullTime)(&reminder.SentAt),
+		id,
+	); err != nil {
+		return reminder, FormatError(err)
+	}
+
+	return reminder, nil
+}
+
+// deleteReminder permanently deletes a reminder by ID.
+func deleteReminder(ctx context.Context, tx *Tx, id int) error {
+	if _, err := findReminderByID(ctx, tx, id); err != nil {
+		return err
+	}
+
+	// Remove row from database.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM reminders WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/havce/ctfbot"
+	"github.com/havce/ctfbot/sqlite"
+)
+
+func TestReminderService_FindDueReminders(t *testing.T) {
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+
+	ctx := context.Background()
+	now := time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC)
+	db.Now = func() time.Time { return now }
+
+	ctf := &ctfbot.CTF{Name: "havcectf", RoleID: "1", Start: now.Add(time.Hour), Finish: now.Add(49 * time.Hour)}
+	if err := sqlite.NewCTFService(db).CreateCTF(ctx, ctf); err != nil {
+		t.Fatal(err)
+	}
+
+	s := sqlite.NewReminderService(db)
+	for _, at := range []time.Time{now.Add(time.Hour), now.Add(-5 * time.Minute), now.Add(-2 * time.Hour)} {
+		if err := s.CreateReminder(ctx, &ctfbot.Reminder{CTFID: ctf.ID, Kind: ctfbot.ReminderStart, At: at}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Ensure only the reminder overdue within the grace period is due.
+	reminders, err := s.FindDueReminders(ctx)
+	if err != nil {
+		t.Fatal(err)
+	} else if got, want := len(reminders), 1; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	} else if got, want := reminders[0].At, now.Add(-5*time.Minute); !got.Equal(want) {
+		t.Fatalf("At=%s, want %s", got, want)
+	}
+
+	// Ensure the one overdue for too long was dropped.
+	sent := true
+	if dropped, _, err := s.FindReminders(ctx, ctfbot.ReminderFilter{Sent: &sent}); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(dropped), 1; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	} else if got, want := dropped[0].At, now.Add(-2*time.Hour); !got.Equal(want) {
+		t.Fatalf("At=%s, want %s", got, want)
+	}
+
+	if _, err := s.MarkReminderSent(ctx, reminders[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing is due until the clock moves.
+	if reminders, err := s.FindDueReminders(ctx); err != nil {
+		t.Fatal(err)
+	} else if len(reminders) != 0 {
+		t.Fatalf("len=%d, want 0", len(reminders))
+	}
+
+	now = now.Add(time.Hour + time.Minute)
+	if reminders, err := s.FindDueReminders(ctx); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(reminders), 1; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	} else if got, want := reminders[0].At, ctf.Start; !got.Equal(want) {
+		t.Fatalf("At=%s, want %s", got, want)
+	}
+}
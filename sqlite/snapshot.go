@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+
+	"github.com/havce/ctfbot"
+)
+
+type SnapshotService struct {
+	db *DB
+}
+
+func NewSnapshotService(db *DB) *SnapshotService {
+	return &SnapshotService{
+		db: db,
+	}
+}
+
+func (s *SnapshotService) FindSnapshots(ctx context.Context, filter ctfbot.SnapshotFilter) ([]*ctfbot.Snapshot, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	return findSnapshots(ctx, tx, filter)
+}
+
+func (s *SnapshotService) CreateSnapshot(ctx context.Context, snapshot *ctfbot.Snapshot) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Create snapshot.
+	if err := createSnapshot(ctx, tx, snapshot); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func findSnapshots(ctx context.Context, tx *Tx, filter ctfbot.SnapshotFilter) (_ []*ctfbot.Snapshot, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+
+	if v := filter.CTFID; v != nil {
+		where, args = append(where, "ctf_id = ?"), append(args, *v)
+	}
+
+	// Execue query with limiting WHERE clause and LIMIT/OFFSET injected.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    id,
+		    ctf_id,
+		    position,
+		    score,
+		    created_at,
+		    COUNT(*) OVER()
+		FROM snapshots
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY created_at DESC, id DESC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into Snapshot objects.
+	snapshots := make([]*ctfbot.Snapshot, 0)
+	for rows.Next() {
+		var snapshot ctfbot.Snapshot
+		if err := rows.Scan(
+			&snapshot.ID,
+			&snapshot.CTFID,
+			&snapshot.Position,
+			&snapshot.Score,
+			(*NullTime)(&snapshot.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return snapshots, n, nil
+}
+
+// createSnapshot creates a new snapshot.
+func createSnapshot(ctx context.Context, tx *Tx, snapshot *ctfbot.Snapshot) error {
+	// Set timestamp to current time.
+	snapshot.CreatedAt = tx.now
+
+	// Perform basic field validation.
+	if err := snapshot.Validate(); err != nil {
+		return err
+	}
+
+	// Insert row into database.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO snapshots (
+			ctf_id,
+			position,
+			score,
+			created_at
+		)
+		VALUES (?, ?, ?, ?)
+	`,
+		snapshot.CTFID,
+		snapshot.Position,
+		snapshot.Score,
+		(*NullTime)(&snapshot.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new snapshot ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	snapshot.ID = int(id)
+
+	return nil
+}
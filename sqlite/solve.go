@@ -0,0 +1,219 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+
+	"github.com/havce/ctfbot"
+)
+
+type SolveService struct {
+	db *DB
+}
+
+func NewSolveService(db *DB) *SolveService {
+	return &SolveService{
+		db: db,
+	}
+}
+
+func (s *SolveService) FindSolves(ctx context.Context, filter ctfbot.SolveFilter) ([]*ctfbot.Solve, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	return findSolves(ctx, tx, filter)
+}
+
+func (s *SolveService) CreateSolve(ctx context.Context, solve *ctfbot.Solve) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Create solve.
+	if err := createSolve(ctx, tx, solve); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SolveService) DeleteSolve(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := deleteSolve(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func findSolveByID(ctx context.Context, tx *Tx, id int) (*ctfbot.Solve, error) {
+	solves, _, err := findSolves(ctx, tx, ctfbot.SolveFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(solves) == 0 {
+		return nil, ctfbot.Errorf(ctfbot.ENOTFOUND, "Solve not found.")
+	}
+	return solves[0], nil
+}
+
+func findSolves(ctx context.Context, tx *Tx, filter ctfbot.SolveFilter) (_ []*ctfbot.Solve, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "s.id = ?"), append(args, *v)
+	}
+
+	if v := filter.ChallengeID; v != nil {
+		where, args = append(where, "s.challenge_id = ?"), append(args, *v)
+	}
+
+	if v := filter.CTFID; v != nil {
+		where, args = append(where, "c.ctf_id = ?"), append(args, *v)
+	}
+
+	if v := filter.UserID; v != nil {
+		where, args = append(where, "s.user_id = ?"), append(args, *v)
+	}
+
+	if v := filter.Blood; v != nil {
+		where, args = append(where, "s.blood = ?"), append(args, *v)
+	}
+
+	// Execue query with limiting WHERE clause and LIMIT/OFFSET injected.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    s.id,
+		    s.challenge_id,
+		    s.user_id,
+		    s.blood,
+		    s.flag,
+		    s.solved_at,
+		    COUNT(*) OVER()
+		FROM solves s
+		INNER JOIN challenges c ON c.id = s.challenge_id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY s.solved_at ASC, s.id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into Solve objects.
+	solves := make([]*ctfbot.Solve, 0)
+	for rows.Next() {
+		var solve ctfbot.Solve
+		if err := rows.Scan(
+			&solve.ID,
+			&solve.ChallengeID,
+			&solve.UserID,
+			&solve.Blood,
+			&solve.Flag,
+			(*NullTime)(&solve.SolvedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		solves = append(solves, &solve)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return solves, n, nil
+}
+
+// createSolve creates a new solve and marks its challenge as solved.
+func createSolve(ctx context.Context, tx *Tx, solve *ctfbot.Solve) error {
+	// Default the solve time to the current time.
+	if solve.SolvedAt.IsZero() {
+		solve.SolvedAt = tx.now
+	}
+
+	// Perform basic field validation.
+	if err := solve.Validate(); err != nil {
+		return err
+	}
+
+	// Make sure the challenge exists and nobody has flagged it yet.
+	if chal, err := findChallengeByID(ctx, tx, solve.ChallengeID); err != nil {
+		return err
+	} else if chal.Solved {
+		return ctfbot.Errorf(ctfbot.ECONFLICT, "Challenge already solved.")
+	}
+
+	// Insert row into database.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO solves (
+			challenge_id,
+			user_id,
+			blood,
+			flag,
+			solved_at
+		)
+		VALUES (?, ?, ?, ?, ?)
+	`,
+		solve.ChallengeID,
+		solve.UserID,
+		solve.Blood,
+		solve.Flag,
+		(*NullTime)(&solve.SolvedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new solve ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	solve.ID = int(id)
+
+	// Reflect the solve on the challenge.
+	solved := true
+	if _, err := updateChallenge(ctx, tx, solve.ChallengeID, ctfbot.ChallengeUpdate{
+		Solved: &solved,
+		Blood:  &solve.Blood,
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// deleteSolve permanently deletes a solve by ID and marks its challenge as
+// unsolved.
+func deleteSolve(ctx context.Context, tx *Tx, id int) error {
+	solve, err := findSolveByID(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	// Remove row from database.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM solves WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+
+	// Reflect the revert on the challenge.
+	solved, blood := false, false
+	if _, err := updateChallenge(ctx, tx, solve.ChallengeID, ctfbot.ChallengeUpdate{
+		Solved: &solved,
+		Blood:  &blood,
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"embed"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -12,7 +13,9 @@ import (
 	"sort"
 	"time"
 
-	_ "modernc.org/sqlite"
+	"github.com/havce/ctfbot"
+	sqlite3 "modernc.org/sqlite"
+	sqlite3lib "modernc.org/sqlite/lib"
 )
 
 //go:embed migration/*.sql
@@ -27,6 +30,9 @@ type DB struct {
 	// Datasource name.
 	DSN string
 
+	// AES key used to encrypt secrets at rest. Must be 16, 24 or 32 bytes long.
+	EncryptionKey []byte
+
 	// Returns the current time. Defaults to time.Now().
 	// Can be mocked for tests.
 	Now func() time.Time
@@ -229,7 +235,14 @@ func FormatError(err error) error {
 		return nil
 	}
 
-	switch err.Error() {
+	var e *sqlite3.Error
+	if !errors.As(err, &e) {
+		return err
+	}
+
+	switch e.Code() {
+	case sqlite3lib.SQLITE_CONSTRAINT_UNIQUE, sqlite3lib.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return ctfbot.Errorf(ctfbot.ECONFLICT, "Already exists.")
 	default:
 		return err
 	}